@@ -14,8 +14,10 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"runtime"
+	"strings"
 	"sync"
 	"time"
 
@@ -23,8 +25,10 @@ import (
 	"github.com/gocsaf/csaf/v3/csaf"
 	"github.com/gocsaf/csaf/v3/internal/certs"
 	"github.com/gocsaf/csaf/v3/internal/filter"
+	"github.com/gocsaf/csaf/v3/internal/metrics"
 	"github.com/gocsaf/csaf/v3/internal/models"
 	"github.com/gocsaf/csaf/v3/internal/options"
+	"github.com/gocsaf/csaf/v3/internal/truststore"
 	"github.com/gocsaf/csaf/v3/util"
 	"golang.org/x/time/rate"
 )
@@ -43,6 +47,8 @@ type provider struct {
 	Domain string `toml:"domain"`
 	// Rate gives the provider specific rate limiting (see overall Rate).
 	Rate         *float64  `toml:"rate"`
+	MaxInFlight  *int      `toml:"max_in_flight"`
+	Proxy        *string   `toml:"proxy"`
 	Insecure     *bool     `toml:"insecure"`
 	WriteIndices *bool     `toml:"write_indices"`
 	Categories   *[]string `toml:"categories"`
@@ -67,6 +73,7 @@ type provider struct {
 
 	clientCerts   []tls.Certificate
 	ignorePattern filter.PatternMatcher
+	proxies       *util.Proxies
 }
 
 type config struct {
@@ -78,6 +85,14 @@ type config struct {
 	Domain  string `toml:"domain"`
 	// Rate gives the average upper limit of https operations per second.
 	Rate                 *float64            `toml:"rate"`
+	Retries              *int                `toml:"retries"`
+	RetryDelay           time.Duration       `toml:"retry_delay"`
+	RetryMaxDelay        time.Duration       `toml:"retry_max_delay"`
+	HostRate             *float64            `toml:"host_rate"`
+	HostMaxInFlight      int                 `toml:"host_max_in_flight"`
+	CacheDir             string              `toml:"cache_dir"`
+	Proxy                string              `toml:"proxy"`
+	MetricsAddress       string              `toml:"metrics_address"`
 	Insecure             *bool               `toml:"insecure"`
 	Categories           *[]string           `toml:"categories"`
 	WriteIndices         bool                `toml:"write_indices"`
@@ -85,6 +100,8 @@ type config struct {
 	Providers            []*provider         `toml:"providers"`
 	OpenPGPPrivateKey    string              `toml:"openpgp_private_key"`
 	OpenPGPPublicKey     string              `toml:"openpgp_public_key"`
+	TrustStore           string              `toml:"trust_store"`
+	TrustPolicy          truststore.Policy   `toml:"trust_policy"`
 	Passphrase           *string             `toml:"passphrase"`
 	AllowSingleProvider  bool                `toml:"allow_single_provider"`
 	StreamingROLIEParser bool                `long:"streaming_rolie_parser" description:"Use the streaming ROLIE feed parser (experimental)" toml:"streaming_rolie_parser"`
@@ -98,6 +115,11 @@ type config struct {
 	// LockFile tries to lock to a given file.
 	LockFile *string `toml:"lock_file"`
 
+	// LogFile is the file to log to. Logs go to STDOUT if not set.
+	LogFile   string            `toml:"log_file"`
+	LogLevel  *options.LogLevel `toml:"log_level"`
+	LogFormat options.LogFormat `toml:"log_format"`
+
 	// Interim performs an interim scan.
 	Interim bool `short:"i" long:"interim" description:"Perform an interim scan" toml:"interim"`
 	Version bool `long:"version" description:"Display version of the binary" toml:"-"`
@@ -122,6 +144,11 @@ type config struct {
 	// ExtraHeader adds extra HTTP header fields to client
 	ExtraHeader http.Header `toml:"header"`
 
+	// Hosts are the limits of the requests to the given hosts.
+	Hosts map[string]util.HostLimit `toml:"hosts"`
+	// Proxies are the proxies to reach the given hosts.
+	Proxies map[string]string `toml:"proxies"`
+
 	Config string `short:"c" long:"config" description:"Path to config TOML file" value-name:"TOML-FILE" toml:"-"`
 
 	keyMu  sync.Mutex
@@ -130,6 +157,10 @@ type config struct {
 
 	clientCerts   []tls.Certificate
 	ignorePattern filter.PatternMatcher
+	trust         *truststore.Store
+	hostLimiter   *util.HostLimiter
+	proxies       *util.Proxies
+	metrics       *aggregatorMetrics
 }
 
 // configPaths are the potential file locations of the config file.
@@ -289,9 +320,15 @@ func (c *config) httpClient(p *provider) util.ClientWithContext {
 		tlsConfig.Certificates = c.clientCerts
 	}
 
+	// Provider has precedence over global.
+	proxies := c.proxies
+	if p.proxies != nil {
+		proxies = p.proxies
+	}
+
 	hClient.Transport = &http.Transport{
 		TLSClientConfig: &tlsConfig,
-		Proxy:           http.ProxyFromEnvironment,
+		Proxy:           proxies.Proxy,
 	}
 
 	client := util.Client(&hClient)
@@ -299,6 +336,14 @@ func (c *config) httpClient(p *provider) util.ClientWithContext {
 	var cwc util.ClientWithContext
 
 	cwc = &util.BasicClient{Client: client}
+
+	// Count the requests.
+	if c.metrics != nil {
+		cwc = &metrics.CountingClient{
+			Client:   cwc,
+			Requests: c.metrics.requests,
+		}
+	}
 	// Add extra headers.
 	switch {
 	// Provider has precedence over global.
@@ -326,21 +371,63 @@ func (c *config) httpClient(p *provider) util.ClientWithContext {
 		}
 	}
 
-	if p.Rate == nil && c.Rate == nil {
-		return cwc
+	if p.Rate != nil || c.Rate != nil {
+		var r float64
+		if c.Rate != nil {
+			r = *c.Rate
+		}
+		if p.Rate != nil {
+			r = *p.Rate
+		}
+		cwc = &util.LimitingClient{
+			Client:  cwc,
+			Limiter: rate.NewLimiter(rate.Limit(r), 1),
+		}
 	}
 
-	var r float64
-	if c.Rate != nil {
-		r = *c.Rate
+	if c.hostLimiter != nil {
+		cwc = &util.HostLimitingClient{
+			Client:  cwc,
+			Limiter: c.hostLimiter,
+		}
 	}
-	if p.Rate != nil {
-		r = *p.Rate
+
+	// Retry transient failures.
+	retries := util.DefaultRetries
+	if c.Retries != nil {
+		retries = *c.Retries
+	}
+	if retries > 0 {
+		cwc = &util.RetryingClient{
+			Client:   cwc,
+			Retries:  retries,
+			Delay:    c.RetryDelay,
+			MaxDelay: c.RetryMaxDelay,
+			Log:      retryLog,
+		}
 	}
-	return &util.LimitingClient{
-		Client:  cwc,
-		Limiter: rate.NewLimiter(rate.Limit(r), 1),
+
+	// Cache the responses for repeated runs.
+	if c.CacheDir != "" {
+		cwc = &util.CachingClient{
+			Client: cwc,
+			Dir:    c.CacheDir,
+			// Do not store access protected advisories.
+			Credentials: len(tlsConfig.Certificates) > 0 ||
+				len(p.ExtraHeader) > 0 || len(c.ExtraHeader) > 0,
+		}
 	}
+	return cwc
+}
+
+// retryLog logs the retries of a [util.RetryingClient].
+func retryLog(method, url string, attempt int, wait time.Duration, reason string) {
+	slog.Warn("Retrying request",
+		"method", method,
+		"url", url,
+		"attempt", attempt,
+		"wait", wait,
+		"reason", reason)
 }
 
 func (c *config) checkProviders() error {
@@ -415,12 +502,18 @@ func (c *config) setDefaults() {
 
 // prepareLogging sets up the structured logging.
 func (c *config) prepareLogging() error {
-	ho := slog.HandlerOptions{
-		Level: slog.LevelDebug,
+	lg := options.Logging{
+		Format: c.LogFormat,
+		Level:  slog.LevelDebug,
+		File:   c.LogFile,
+	}
+	if c.LogLevel != nil {
+		lg.Level = c.LogLevel.Level
+	}
+	if lg.File != "" {
+		return lg.SetDefault()
 	}
-	handler := slog.NewTextHandler(os.Stdout, &ho)
-	logger := slog.New(handler)
-	slog.SetDefault(logger)
+	slog.SetDefault(slog.New(lg.Handler(os.Stdout)))
 	return nil
 }
 
@@ -481,6 +574,91 @@ func (c *config) prepareCertificates() error {
 	return nil
 }
 
+// openTrustStore opens the OpenPGP trust store if configured.
+func (c *config) openTrustStore() error {
+	if c.TrustStore == "" {
+		return nil
+	}
+	trust, err := truststore.Open(c.TrustStore, c.TrustPolicy)
+	if err != nil {
+		return err
+	}
+	c.trust = trust
+	return nil
+}
+
+// host returns the host name of the domain of the provider.
+func (p *provider) host() string {
+	if strings.HasPrefix(p.Domain, "https://") {
+		if u, err := url.Parse(p.Domain); err == nil {
+			return u.Hostname()
+		}
+	}
+	return p.Domain
+}
+
+// prepareHostLimits creates the limiter of the requests per host
+// shared by the clients of all providers if needed.
+func (c *config) prepareHostLimits() error {
+	var def util.HostLimit
+	if c.HostRate != nil {
+		def.Rate = *c.HostRate
+	}
+	def.MaxInFlight = c.HostMaxInFlight
+	hosts := make(map[string]util.HostLimit, len(c.Hosts))
+	for host, l := range c.Hosts {
+		hosts[strings.ToLower(host)] = l
+	}
+	for _, p := range c.Providers {
+		if p.MaxInFlight == nil {
+			continue
+		}
+		host := strings.ToLower(p.host())
+		l, ok := hosts[host]
+		if !ok {
+			l = def
+		}
+		l.MaxInFlight = *p.MaxInFlight
+		hosts[host] = l
+	}
+	if def == (util.HostLimit{}) && len(hosts) == 0 {
+		return nil
+	}
+	if def.Rate < 0 || def.MaxInFlight < 0 {
+		return fmt.Errorf("invalid host limits: rate %g, max in flight %d", def.Rate, def.MaxInFlight)
+	}
+	for host, l := range hosts {
+		if l.Rate < 0 || l.MaxInFlight < 0 {
+			return fmt.Errorf("invalid limits of host %q", host)
+		}
+	}
+	c.hostLimiter = util.NewHostLimiter(def, hosts)
+	return nil
+}
+
+// prepareProxies sets up the global proxies and
+// the ones of the providers if configured.
+func (c *config) prepareProxies() error {
+	if c.Proxy != "" || len(c.Proxies) > 0 {
+		proxies, err := util.NewProxies(c.Proxy, c.Proxies)
+		if err != nil {
+			return err
+		}
+		c.proxies = proxies
+	}
+	for _, p := range c.Providers {
+		if p.Proxy == nil {
+			continue
+		}
+		proxies, err := util.NewProxies(*p.Proxy, nil)
+		if err != nil {
+			return fmt.Errorf("provider %q: %w", p.Name, err)
+		}
+		p.proxies = proxies
+	}
+	return nil
+}
+
 // prepare prepares internal state of a loaded configuration.
 func (c *config) prepare() error {
 	if len(c.Providers) == 0 {
@@ -493,6 +671,10 @@ func (c *config) prepare() error {
 		c.Aggregator.Validate,
 		c.checkProviders,
 		c.checkMirror,
+		c.openTrustStore,
+		c.prepareHostLimits,
+		c.prepareProxies,
+		c.prepareMetrics,
 	} {
 		if err := prepare(); err != nil {
 			return err
@@ -0,0 +1,81 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"testing"
+
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+func TestProviderHost(t *testing.T) {
+	for domain, want := range map[string]string{
+		"example.com": "example.com",
+		"https://example.com/.well-known/csaf/provider-metadata.json": "example.com",
+		"https://example.com:8443/provider-metadata.json":             "example.com",
+	} {
+		if got := (&provider{Domain: domain}).host(); got != want {
+			t.Errorf("%s: got %q, want %q", domain, got, want)
+		}
+	}
+}
+
+func TestPrepareHostLimits(t *testing.T) {
+	two, neg := 2, -1
+
+	c := &config{}
+	if err := c.prepareHostLimits(); err != nil {
+		t.Fatal(err)
+	}
+	if c.hostLimiter != nil {
+		t.Error("limiter created without limits")
+	}
+
+	c = &config{
+		Hosts: map[string]util.HostLimit{"cdn.example.com": {Rate: 1}},
+		Providers: []*provider{
+			{Name: "a", Domain: "https://a.example.com/provider-metadata.json", MaxInFlight: &two},
+			{Name: "b", Domain: "b.example.com"},
+		},
+	}
+	if err := c.prepareHostLimits(); err != nil {
+		t.Fatal(err)
+	}
+	if c.hostLimiter == nil {
+		t.Error("no limiter created")
+	}
+
+	c.Providers[1].MaxInFlight = &neg
+	if err := c.prepareHostLimits(); err == nil {
+		t.Error("negative limit accepted")
+	}
+}
+
+func TestPrepareProxies(t *testing.T) {
+	proxy, invalid := "socks5://socks.example.com:1080", "ftp://proxy.example.com"
+
+	c := &config{
+		Proxy: "http://proxy.example.com:3128",
+		Providers: []*provider{
+			{Name: "a", Domain: "a.example.com", Proxy: &proxy},
+			{Name: "b", Domain: "b.example.com"},
+		},
+	}
+	if err := c.prepareProxies(); err != nil {
+		t.Fatal(err)
+	}
+	if c.proxies == nil || c.Providers[0].proxies == nil || c.Providers[1].proxies != nil {
+		t.Error("proxies not set up as configured")
+	}
+
+	c.Providers[1].Proxy = &invalid
+	if err := c.prepareProxies(); err == nil {
+		t.Error("invalid proxy accepted")
+	}
+}
@@ -9,19 +9,10 @@
 package main
 
 import (
-	"fmt"
 	"os"
-)
 
-// writeHash writes a hash to file.
-func writeHash(fname, name string, hash []byte) error {
-	f, err := os.Create(fname)
-	if err != nil {
-		return err
-	}
-	fmt.Fprintf(f, "%x %s\n", hash, name)
-	return f.Close()
-}
+	"github.com/gocsaf/csaf/v3/internal/indices"
+)
 
 // writeFileHashes writes a file and its hashes to files.
 func writeFileHashes(fname, name string, data, s256, s512 []byte) error {
@@ -30,9 +21,9 @@ func writeFileHashes(fname, name string, data, s256, s512 []byte) error {
 		return err
 	}
 	// Write SHA256 sum.
-	if err := writeHash(fname+".sha256", name, s256); err != nil {
+	if err := indices.WriteHash(fname+".sha256", name, s256); err != nil {
 		return err
 	}
 	// Write SHA512 sum.
-	return writeHash(fname+".sha512", name, s512)
+	return indices.WriteHash(fname+".sha512", name, s512)
 }
@@ -33,16 +33,8 @@ type fullJob struct {
 
 // setupProviderFull fetches the provider-metadata.json for a specific provider.
 func (w *worker) setupProviderFull(ctx context.Context, provider *provider) error {
-	w.log.Info("Setting up provider",
-		"provider", slog.GroupValue(
-			slog.String("name", provider.Name),
-			slog.String("domain", provider.Domain),
-		))
-	w.dir = ""
-	w.provider = provider
-
-	// Each job needs a separate client.
-	w.client = w.processor.cfg.httpClient(provider)
+	w.setProvider(provider)
+	w.log.Info("Setting up provider")
 
 	// We need the provider metadata in all cases.
 	if err := w.locateProviderMetadata(ctx, provider.Domain); err != nil {
@@ -130,9 +122,11 @@ func (p *processor) full(ctx context.Context) error {
 			provider: provider,
 			work:     work,
 		}
+		p.cfg.metrics.queued("providers", len(jobs)-i)
 		queue <- &jobs[i]
 	}
 	close(queue)
+	p.cfg.metrics.queued("providers", 0)
 
 	wg.Wait()
 
@@ -148,7 +142,7 @@ func (p *processor) full(ctx context.Context) error {
 					slog.Group("provider"),
 					"name", j.provider.Name,
 				),
-				"err", j.err,
+				"error", j.err,
 			)
 			continue
 		}
@@ -65,10 +65,12 @@ func (w *worker) checkInterims(
 
 		res, err := w.client.GetWithContext(ctx, url)
 		if err != nil {
+			w.processor.cfg.metrics.failure("download")
 			return err
 		}
 		if res.StatusCode != http.StatusOK {
 			res.Body.Close()
+			w.processor.cfg.metrics.failure("download")
 			return fmt.Errorf("fetching %s failed: Status code %d (%s)",
 				url, res.StatusCode, res.Status)
 		}
@@ -84,6 +86,7 @@ func (w *worker) checkInterims(
 		err = misc.StrictJSONParse(tee, &doc)
 		res.Body.Close()
 		if err != nil {
+			w.processor.cfg.metrics.failure("download")
 			return err
 		}
 
@@ -92,17 +95,19 @@ func (w *worker) checkInterims(
 		// If the hashes are equal then we can ignore this advisory.
 		if bytes.Equal(localHash, remoteHash) {
 			notFinalized = append(notFinalized, interim)
+			w.processor.cfg.metrics.advisory("not_modified")
 			return nil
 		}
 
 		errors, err := csaf.ValidateCSAF(doc)
 		if err != nil {
+			w.processor.cfg.metrics.failure("schema")
 			return fmt.Errorf("failed to validate %s: %v", url, err)
 		}
 
 		// XXX: Should we return an error here?
 		for _, e := range errors {
-			w.log.Error("validation error", "url", url, "err", e)
+			w.log.Error("validation error", "url", url, "error", e)
 		}
 
 		// We need to write the changed content.
@@ -141,6 +146,7 @@ func (w *worker) checkInterims(
 
 		// Download the signature or sign it our self.
 		if err := w.downloadSignatureOrSign(ctx, sigURL, ascFile, bytes); err != nil {
+			w.processor.cfg.metrics.failure("signature")
 			return err
 		}
 
@@ -152,6 +158,7 @@ func (w *worker) checkInterims(
 		if status == "interim" {
 			notFinalized = append(notFinalized, interim)
 		}
+		w.processor.cfg.metrics.advisory("succeeded")
 		return nil
 	}
 
@@ -166,13 +173,8 @@ func (w *worker) checkInterims(
 
 // setupProviderInterim prepares the worker for a specific provider.
 func (w *worker) setupProviderInterim(provider *provider) {
-	w.log.Info("Setting up worker", provider.Name, provider.Domain)
-
-	w.dir = ""
-	w.provider = provider
-
-	// Each job needs a separate client.
-	w.client = w.processor.cfg.httpClient(provider)
+	w.setProvider(provider)
+	w.log.Info("Setting up worker")
 }
 
 func (w *worker) interimWork(ctx context.Context, wg *sync.WaitGroup, jobs <-chan *interimJob) {
@@ -279,11 +281,13 @@ func (p *processor) interim(ctx context.Context) error {
 
 	jobs := make([]interimJob, len(p.cfg.Providers))
 
-	for i, p := range p.cfg.Providers {
-		jobs[i] = interimJob{provider: p}
+	for i, provider := range p.cfg.Providers {
+		jobs[i] = interimJob{provider: provider}
+		p.cfg.metrics.queued("providers", len(jobs)-i)
 		queue <- &jobs[i]
 	}
 	close(queue)
+	p.cfg.metrics.queued("providers", 0)
 
 	wg.Wait()
 
@@ -46,9 +46,12 @@ func lock(lockFile *string, fn func() error) error {
 
 func main() {
 	_, cfg, err := parseArgsConfig()
-	cfg.prepareLogging()
 	options.ErrorCheckStructured(err)
+	options.ErrorCheckStructured(cfg.prepareLogging())
 	options.ErrorCheckStructured(cfg.prepare())
 	p := processor{cfg: cfg, log: slog.Default()}
-	options.ErrorCheckStructured(lock(cfg.LockFile, p.process))
+	stop := cfg.metrics.serve(cfg.MetricsAddress)
+	err = lock(cfg.LockFile, p.process)
+	stop()
+	options.ErrorCheckStructured(err)
 }
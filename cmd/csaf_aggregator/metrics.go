@@ -0,0 +1,69 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import "github.com/gocsaf/csaf/v3/internal/metrics"
+
+// aggregatorMetrics are the metrics exposed by the aggregator.
+// All methods do nothing on a nil receiver.
+type aggregatorMetrics struct {
+	registry  *metrics.Registry
+	requests  *metrics.Counter
+	processed *metrics.Counter
+	failed    *metrics.Counter
+	queue     *metrics.Gauge
+}
+
+// prepareMetrics creates the metrics if the
+// metrics endpoint is configured.
+func (c *config) prepareMetrics() error {
+	if c.MetricsAddress == "" {
+		return nil
+	}
+	r := metrics.NewRegistry()
+	c.metrics = &aggregatorMetrics{
+		registry:  r,
+		requests:  r.Requests(),
+		processed: r.Processed(),
+		failed:    r.Failed(),
+		queue:     r.QueueDepth(),
+	}
+	return nil
+}
+
+// serve starts the metrics endpoint.
+// The returned function shuts it down.
+func (m *aggregatorMetrics) serve(addr string) func() {
+	if m == nil {
+		return func() {}
+	}
+	return m.registry.Serve(addr)
+}
+
+// advisory counts a processed advisory with the given result.
+func (m *aggregatorMetrics) advisory(result string) {
+	if m != nil {
+		m.processed.Inc(result)
+	}
+}
+
+// failure counts a failed advisory with the given reason.
+func (m *aggregatorMetrics) failure(reason string) {
+	if m != nil {
+		m.processed.Inc("failed")
+		m.failed.Inc(reason)
+	}
+}
+
+// queued sets the depth of a queue.
+func (m *aggregatorMetrics) queued(queue string, n int) {
+	if m != nil {
+		m.queue.Set(float64(n), queue)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -31,6 +32,7 @@ import (
 	"github.com/ProtonMail/gopenpgp/v2/crypto"
 
 	"github.com/gocsaf/csaf/v3/csaf"
+	"github.com/gocsaf/csaf/v3/internal/indices"
 	"github.com/gocsaf/csaf/v3/internal/misc"
 	"github.com/gocsaf/csaf/v3/util"
 )
@@ -48,7 +50,7 @@ func (w *worker) mirror(ctx context.Context) (*csaf.AggregatorCSAFProvider, erro
 	if err != nil && w.dir != "" {
 		// If something goes wrong remove the debris.
 		if err := os.RemoveAll(w.dir); err != nil {
-			w.log.Error("Could not remove directory", "path", w.dir, "err", err)
+			w.log.Error("Could not remove directory", "path", w.dir, "error", err)
 		}
 	}
 	return result, err
@@ -64,8 +66,13 @@ func (w *worker) mirrorInternal(
 			"no mirroring of '%s' allowed", w.provider.Name)
 	}
 
+	// Load the keys to check the signatures with.
+	if err := w.loadTrustedKeys(ctx); err != nil {
+		return nil, err
+	}
+
 	// Collecting the summaries of the advisories.
-	w.summaries = make(map[string][]summary)
+	w.summaries = make(map[string][]indices.Summary)
 
 	// Collecting the categories per label.
 	w.categories = map[string]util.Set[string]{}
@@ -177,7 +184,7 @@ func (w *worker) writeProviderMetadata(ctx context.Context) error {
 		{Expr: `$.public_openpgp_keys`, Action: util.ReMarshalMatcher(&pm.PGPKeys)},
 	}, w.metadataProvider); err != nil {
 		// only log the errors
-		w.log.Error("Extracting data from original provider failed", "err", err)
+		w.log.Error("Extracting data from original provider failed", "error", err)
 	}
 
 	// We are mirroring the remote public keys, too.
@@ -207,6 +214,16 @@ func (w *worker) mirrorPGPKeys(ctx context.Context, pm *csaf.ProviderMetadata) e
 		return keyURL.JoinPath("openpgp", (fingerprint + ".asc")).String()
 	}
 
+	// Only mirror the keys allowed by the trust store.
+	pm.PGPKeys = slices.DeleteFunc(pm.PGPKeys, func(key csaf.PGPKey) bool {
+		if w.trustedKey(string(key.Fingerprint)) {
+			return false
+		}
+		w.log.Warn("Not mirroring PGP key not allowed by trust store",
+			"fingerprint", key.Fingerprint)
+		return true
+	})
+
 	for i := range pm.PGPKeys {
 		pgpKey := &pm.PGPKeys[i]
 		if pgpKey.URL == nil {
@@ -480,7 +497,7 @@ func (w *worker) extractCategories(label string, advisory any) error {
 			if _, err := w.expr.Compile(expr); err != nil {
 				slog.Error("Compiling category expression failed",
 					"expr", expr,
-					"err", err)
+					"error", err)
 				continue
 			}
 			// Ignore errors here as they result from not matching.
@@ -521,25 +538,29 @@ func (w *worker) mirrorFilesWithContext(
 	yearDirs := make(map[int]string)
 
 	mirrorFile := func(file csaf.AdvisoryFile) error {
+		log := w.log.With("url", file.URL())
 
 		u, err := url.Parse(file.URL())
 		if err != nil {
-			w.log.Error("Could not parse advisory file URL", "err", err)
+			log.Error("Could not parse advisory file URL", "error", err)
+			w.processor.cfg.metrics.failure("download")
 			return nil
 		}
 
 		// Should we ignore this advisory?
 		if w.provider.ignoreURL(file.URL(), w.processor.cfg) {
 			if w.processor.cfg.Verbose {
-				w.log.Info("Ignoring advisory", slog.Group("provider", "name", w.provider.Name), "file", file)
+				log.Info("Ignoring advisory")
 			}
+			w.processor.cfg.metrics.advisory("filtered")
 			return nil
 		}
 
 		// Ignore not conforming filenames.
 		filename := filepath.Base(u.Path)
 		if !util.ConformingFileName(filename) {
-			w.log.Warn("Ignoring advisory because of non-conforming filename", "filename", filename)
+			log.Warn("Ignoring advisory because of non-conforming filename", "filename", filename)
+			w.processor.cfg.metrics.failure("filename")
 			return nil
 		}
 
@@ -557,18 +578,21 @@ func (w *worker) mirrorFilesWithContext(
 		}
 
 		if err := downloadJSON(ctx, w.client, file.URL(), download); err != nil {
-			w.log.Error("Error while downloading JSON", "err", err)
+			log.Error("Error while downloading JSON", "error", err)
+			w.processor.cfg.metrics.failure("download")
 			return nil
 		}
 
 		// Check against CSAF schema.
 		errors, err := csaf.ValidateCSAF(advisory)
 		if err != nil {
-			w.log.Error("Error while validating CSAF schema", "err", err)
+			log.Error("Error while validating CSAF schema", "error", err)
+			w.processor.cfg.metrics.failure("schema")
 			return nil
 		}
 		if len(errors) > 0 {
-			w.log.Error("CSAF file has validation errors", "num.errors", len(errors), "file", file)
+			log.Error("CSAF file has validation errors", "num.errors", len(errors))
+			w.processor.cfg.metrics.failure("schema")
 			return nil
 		}
 
@@ -576,34 +600,38 @@ func (w *worker) mirrorFilesWithContext(
 		if rmv := w.processor.remoteValidator; rmv != nil {
 			rvr, err := rmv.ValidateWithContext(ctx, advisory)
 			if err != nil {
-				w.log.Error("Calling remote validator failed", "err", err)
+				log.Error("Calling remote validator failed", "error", err)
+				w.processor.cfg.metrics.failure("remote")
 				return nil
 			}
 			if !rvr.Valid {
-				w.log.Error("CSAF file does not validate remotely", "file", file.URL())
+				log.Error("CSAF file does not validate remotely")
+				w.processor.cfg.metrics.failure("remote")
 				return nil
 			}
 		}
 
 		sum, err := csaf.NewAdvisorySummary(w.expr, advisory)
 		if err != nil {
-			w.log.Error("Error while creating new advisory", "file", file, "err", err)
+			log.Error("Error while creating new advisory", "error", err)
+			w.processor.cfg.metrics.failure("summary")
 			return nil
 		}
 
 		if util.CleanFileName(sum.ID) != filename {
-			w.log.Error("ID mismatch", "id", sum.ID, "filename", filename)
+			log.Error("ID mismatch", "advisory", sum.ID, "filename", filename)
 		}
 
 		if err := w.extractCategories(label, advisory); err != nil {
-			w.log.Error("Could not extract categories", "file", file, "err", err)
+			log.Error("Could not extract categories", "advisory", sum.ID, "error", err)
+			w.processor.cfg.metrics.failure("categories")
 			return nil
 		}
 
-		summaries = append(summaries, summary{
-			filename: filename,
-			summary:  sum,
-			url:      file.URL(),
+		summaries = append(summaries, indices.Summary{
+			Filename: filename,
+			Summary:  sum,
+			URL:      file.URL(),
 		})
 
 		year := sum.InitialReleaseDate.Year()
@@ -629,7 +657,21 @@ func (w *worker) mirrorFilesWithContext(
 		// Try to fetch signature file.
 		sigURL := file.SignURL()
 		ascFile := fname + ".asc"
-		return w.downloadSignatureOrSign(ctx, sigURL, ascFile, data)
+		err = w.downloadSignatureOrSign(ctx, sigURL, ascFile, data)
+		if err == errUntrustedSignature || err == errMissingSignature {
+			// Do not mirror advisories with missing or untrusted signatures.
+			log.Error("Ignoring advisory", "advisory", sum.ID, "error", err)
+			summaries = summaries[:len(summaries)-1]
+			for _, ext := range []string{"", ".sha256", ".sha512"} {
+				os.Remove(fname + ext)
+			}
+			w.processor.cfg.metrics.failure("signature")
+			return nil
+		}
+		if err == nil {
+			w.processor.cfg.metrics.advisory("succeeded")
+		}
+		return err
 	}
 
 	for _, file := range files {
@@ -643,12 +685,21 @@ func (w *worker) mirrorFilesWithContext(
 }
 
 // downloadSignatureOrSign first tries to download a signature.
-// If this fails it creates a signature itself with the configured key.
+// If this fails it creates a signature itself with the configured key
+// unless the trust store requires a signature of the provider.
 func (w *worker) downloadSignatureOrSign(ctx context.Context, url, fname string, data []byte) error {
 	sig, err := w.downloadSignature(ctx, url)
-	if err != nil {
+	if err == nil {
+		if err := w.checkSignature(data, sig); err != nil {
+			return err
+		}
+	} else {
 		if err != errNotFound {
-			w.log.Error("Could not find signature URL", "url", url, "err", err)
+			w.log.Error("Could not find signature URL", "url", url, "error", err)
+		}
+		// Do not vouch for advisories whose origin cannot be checked.
+		if w.requireSignature() {
+			return errMissingSignature
 		}
 		// Sign it our self.
 		if sig, err = w.sign(data); err != nil {
@@ -18,6 +18,7 @@ import (
 	"path/filepath"
 
 	"github.com/gocsaf/csaf/v3/csaf"
+	"github.com/gocsaf/csaf/v3/internal/indices"
 	"github.com/gocsaf/csaf/v3/internal/misc"
 	"github.com/gocsaf/csaf/v3/util"
 
@@ -35,27 +36,22 @@ type processor struct {
 	log *slog.Logger
 }
 
-type summary struct {
-	filename string
-	summary  *csaf.AdvisorySummary
-	url      string
-}
-
 type worker struct {
 	num       int
 	processor *processor
 
 	expr     *util.PathEval
 	signRing *crypto.KeyRing
-
-	client           util.ClientWithContext      // client per provider
-	provider         *provider                   // current provider
-	metadataProvider any                         // current metadata provider
-	loc              string                      // URL of current provider-metadata.json
-	dir              string                      // Directory to store data to.
-	summaries        map[string][]summary        // the summaries of the advisories.
-	categories       map[string]util.Set[string] // the categories per label.
-	log              *slog.Logger                // the structured logger, supplied with the worker number.
+	keys     *crypto.KeyRing // keys allowed by the trust store
+
+	client           util.ClientWithContext       // client per provider
+	provider         *provider                    // current provider
+	metadataProvider any                          // current metadata provider
+	loc              string                       // URL of current provider-metadata.json
+	dir              string                       // Directory to store data to.
+	summaries        map[string][]indices.Summary // the summaries of the advisories.
+	categories       map[string]util.Set[string]  // the categories per label.
+	log              *slog.Logger                 // the structured logger, supplied with the worker number.
 	pool             misc.BufferPool
 }
 
@@ -69,6 +65,20 @@ func newWorker(num int, processor *processor, pool misc.BufferPool) *worker {
 	}
 }
 
+// setProvider makes provider the current one of the worker.
+// Its name and domain are added to the logged records.
+func (w *worker) setProvider(provider *provider) {
+	w.dir = ""
+	w.provider = provider
+	w.log = w.processor.log.With(
+		slog.Int("worker", w.num),
+		slog.String("provider", provider.Name),
+		slog.String("domain", provider.Domain))
+
+	// Each job needs a separate client.
+	w.client = w.processor.cfg.httpClient(provider)
+}
+
 func ensureDir(path string) error {
 	_, err := os.Stat(path)
 	if err != nil && os.IsNotExist(err) {
@@ -169,7 +179,7 @@ func (p *processor) removeOrphans() error {
 
 		fi, err := entry.Info()
 		if err != nil {
-			p.log.Error("Could not retrieve file info", "err", err)
+			p.log.Error("Could not retrieve file info", "error", err)
 			continue
 		}
 
@@ -181,13 +191,13 @@ func (p *processor) removeOrphans() error {
 		d := filepath.Join(path, entry.Name())
 		r, err := filepath.EvalSymlinks(d)
 		if err != nil {
-			p.log.Error("Could not evaluate symlink", "err", err)
+			p.log.Error("Could not evaluate symlink", "error", err)
 			continue
 		}
 
 		fd, err := os.Stat(r)
 		if err != nil {
-			p.log.Error("Could not retrieve file stats", "err", err)
+			p.log.Error("Could not retrieve file stats", "error", err)
 			continue
 		}
 
@@ -199,7 +209,7 @@ func (p *processor) removeOrphans() error {
 		// Remove the link.
 		p.log.Info("Removing link", "path", fmt.Sprintf("%s -> %s", d, r))
 		if err := os.Remove(d); err != nil {
-			p.log.Error("Could not remove symlink", "err", err)
+			p.log.Error("Could not remove symlink", "error", err)
 			continue
 		}
 
@@ -208,7 +218,7 @@ func (p *processor) removeOrphans() error {
 			rel == filepath.Base(r) {
 			p.log.Info("Remove directory", "path", r)
 			if err := os.RemoveAll(r); err != nil {
-				p.log.Error("Could not remove directory", "err", err)
+				p.log.Error("Could not remove directory", "error", err)
 			}
 		}
 	}
@@ -0,0 +1,156 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+
+	"github.com/gocsaf/csaf/v3/csaf"
+	"github.com/gocsaf/csaf/v3/internal/truststore"
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+// errUntrustedSignature is returned if a signature is
+// not made by a key allowed by the trust store.
+var errUntrustedSignature = errors.New("signature not made by a trusted key")
+
+// errMissingSignature is returned if an advisory comes without
+// a signature but the trust store does not allow to sign it
+// on behalf of the provider.
+var errMissingSignature = errors.New("advisory has no signature")
+
+// fetchPGPKey downloads a public OpenPGP key and checks
+// that its fingerprint matches the announced one.
+// It returns the armored key and the parsed key.
+func (w *worker) fetchPGPKey(ctx context.Context, pgpKey *csaf.PGPKey) ([]byte, *crypto.Key, error) {
+	if pgpKey.URL == nil {
+		return nil, nil, fmt.Errorf("key %s has no URL", pgpKey.Fingerprint)
+	}
+	res, err := w.client.GetWithContext(ctx, *pgpKey.URL)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("cannot fetch PGP key %s: %s (%d)",
+			*pgpKey.URL, res.Status, res.StatusCode)
+	}
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := crypto.NewKeyFromArmoredReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	if !strings.EqualFold(key.GetFingerprint(), string(pgpKey.Fingerprint)) {
+		return nil, nil, fmt.Errorf(
+			"fingerprint %s of PGP key %s does not match announced %s",
+			key.GetFingerprint(), *pgpKey.URL, pgpKey.Fingerprint)
+	}
+	return data, key, nil
+}
+
+// trustDomain returns the domain of the current provider
+// used as key in the trust store.
+func (w *worker) trustDomain() (string, error) {
+	u, err := url.Parse(w.loc)
+	if err != nil {
+		return "", err
+	}
+	return u.Hostname(), nil
+}
+
+// loadTrustedKeys loads the OpenPGP keys of the current provider
+// and checks them against the trust store if configured.
+// The keys allowed by the store are used to verify the
+// signatures of the mirrored advisories.
+func (w *worker) loadTrustedKeys(ctx context.Context) error {
+	w.keys = nil
+	store := w.processor.cfg.trust
+	if store == nil {
+		return nil
+	}
+	var pgpKeys []csaf.PGPKey
+	if err := w.expr.Extract(
+		`$.public_openpgp_keys`, util.ReMarshalMatcher(&pgpKeys), true, w.metadataProvider,
+	); err != nil {
+		return err
+	}
+	var keys []*crypto.Key
+	for i := range pgpKeys {
+		_, key, err := w.fetchPGPKey(ctx, &pgpKeys[i])
+		if err != nil {
+			w.log.Warn("Loading OpenPGP key failed", "error", err)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	domain, err := w.trustDomain()
+	if err != nil {
+		return err
+	}
+	if w.keys, err = store.KeyRing(domain, keys); err != nil {
+		return fmt.Errorf("checking OpenPGP keys against trust store failed: %w", err)
+	}
+	return nil
+}
+
+// requireSignature returns true if advisories have to come
+// with a signature of the provider. This is the case if a
+// trust store is configured which does not trust on first use.
+func (w *worker) requireSignature() bool {
+	store := w.processor.cfg.trust
+	return store != nil && store.Policy() != truststore.PolicyTOFU
+}
+
+// trustedKey returns true if the key with the given fingerprint
+// may be mirrored.
+func (w *worker) trustedKey(fingerprint string) bool {
+	if w.processor.cfg.trust == nil {
+		return true
+	}
+	if w.keys == nil {
+		return false
+	}
+	for _, key := range w.keys.GetKeys() {
+		if strings.EqualFold(key.GetFingerprint(), fingerprint) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSignature checks if the armored signature of the given
+// data is made by one of the keys allowed by the trust store.
+// It returns errUntrustedSignature if this is not the case.
+func (w *worker) checkSignature(data []byte, armored string) error {
+	if w.keys == nil {
+		return nil
+	}
+	sig, err := crypto.NewPGPSignatureFromArmored(armored)
+	if err == nil {
+		err = w.keys.VerifyDetached(
+			crypto.NewPlainMessage(data), sig, crypto.GetUnixTime())
+	}
+	if err != nil {
+		w.log.Debug("Verifying signature failed", "error", err)
+		return errUntrustedSignature
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gocsaf/csaf/v3/internal/truststore"
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+func TestDownloadSignatureOrSignMissing(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	for _, policy := range []truststore.Policy{
+		truststore.PolicyReject,
+		truststore.PolicyStrict,
+	} {
+		t.Run(string(policy), func(t *testing.T) {
+			dir := t.TempDir()
+			store, err := truststore.Open(filepath.Join(dir, "trust.json"), policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := &processor{
+				cfg: &config{trust: store},
+				log: slog.New(slog.NewTextHandler(io.Discard, nil)),
+			}
+			w := newWorker(0, p, nil)
+			w.client = &util.BasicClient{Client: server.Client()}
+
+			ascFile := filepath.Join(dir, "advisory.json.asc")
+			err = w.downloadSignatureOrSign(
+				context.Background(), server.URL+"/advisory.json.asc", ascFile, []byte("{}"))
+			if err != errMissingSignature {
+				t.Fatalf("got error %v, want %v", err, errMissingSignature)
+			}
+			if _, err := os.Stat(ascFile); !os.IsNotExist(err) {
+				t.Error("signature written for advisory without signature")
+			}
+		})
+	}
+}
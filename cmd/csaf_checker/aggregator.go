@@ -0,0 +1,658 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+
+	"github.com/gocsaf/csaf/v3/csaf"
+	"github.com/gocsaf/csaf/v3/internal/misc"
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+// isAggregatorURL checks if the given domain is a direct
+// URL of an aggregator.json.
+func isAggregatorURL(domain string) bool {
+	return strings.HasPrefix(domain, "https://") &&
+		strings.HasSuffix(domain, "/aggregator.json")
+}
+
+// discoverAggregator looks for an aggregator.json in the
+// well-known location of the given domain. It returns
+// its URL if found, an empty string otherwise.
+func (p *processor) discoverAggregator(ctx context.Context, domain string) string {
+	if strings.HasPrefix(domain, "https://") {
+		return ""
+	}
+	aggURL := "https://" + domain + "/.well-known/csaf-aggregator/aggregator.json"
+	res, err := p.httpClient().GetWithContext(ctx, aggURL)
+	if err != nil {
+		return ""
+	}
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return ""
+	}
+	return aggURL
+}
+
+// listedParty is a provider or publisher listed in an aggregator.json.
+type listedParty struct {
+	kind     string
+	metadata *csaf.AggregatorCSAFProviderMetadata
+	mirrors  []csaf.ProviderURL
+}
+
+// String implements [fmt.Stringer].
+func (lp *listedParty) String() string {
+	if md := lp.metadata; md != nil {
+		if md.Publisher != nil && md.Publisher.Name != nil {
+			return fmt.Sprintf("%s %q", lp.kind, *md.Publisher.Name)
+		}
+		if md.URL != nil {
+			return lp.kind + " " + string(*md.URL)
+		}
+	}
+	return "unnamed " + lp.kind
+}
+
+// namespace returns the namespace of the publisher of
+// the listed party if available.
+func (lp *listedParty) namespace() string {
+	if md := lp.metadata; md != nil && md.Publisher != nil && md.Publisher.Namespace != nil {
+		return *md.Publisher.Namespace
+	}
+	return ""
+}
+
+// listedParties returns the providers and publishers of the given aggregator.
+func listedParties(agg *csaf.Aggregator) []*listedParty {
+	parties := make([]*listedParty, 0, len(agg.CSAFProviders)+len(agg.CSAFPublishers))
+	for _, pr := range agg.CSAFProviders {
+		if pr != nil {
+			parties = append(parties, &listedParty{
+				kind:     "provider",
+				metadata: pr.Metadata,
+				mirrors:  pr.Mirrors,
+			})
+		}
+	}
+	for _, pu := range agg.CSAFPublishers {
+		if pu != nil {
+			parties = append(parties, &listedParty{
+				kind:     "publisher",
+				metadata: pu.Metadata,
+				mirrors:  pu.Mirrors,
+			})
+		}
+	}
+	return parties
+}
+
+// runAggregator checks an aggregator.json given by its URL
+// and the providers and mirrors listed in it. The results
+// are reported under the given name.
+func (p *processor) runAggregator(ctx context.Context, name, aggURL string) *Domain {
+	domain := &Domain{Name: name}
+
+	rules := aggregatorRules
+	if agg := p.checkAggregator(ctx, aggURL); agg != nil && agg.Aggregator != nil {
+		domain.Aggregator = agg.Aggregator
+		if cat := agg.Aggregator.Category; cat != nil && *cat == csaf.AggregatorLister {
+			rules = listerRules
+		}
+	}
+
+	// 21, 22 should always be checked.
+	for _, r := range rules.reporters([]int{21, 22}) {
+		r.report(p, domain)
+	}
+
+	for _, r := range informationalReporters {
+		r.report(p, domain)
+	}
+
+	if evaluated := rules.eval(p); evaluated != nil {
+		domain.EvaluatedRules = evaluated
+		domain.Passed = evaluated.passed()
+	}
+	return domain
+}
+
+// checkAggregator loads the aggregator.json from the given URL,
+// validates it and checks the listed providers and their mirrors.
+func (p *processor) checkAggregator(ctx context.Context, aggURL string) *csaf.Aggregator {
+	agg := p.loadAggregator(ctx, aggURL)
+	if agg == nil {
+		return nil
+	}
+
+	au, err := url.Parse(aggURL)
+	if err != nil {
+		p.badAggregator.error("Invalid URL %s: %v", aggURL, err)
+		return agg
+	}
+	base, err := util.BaseURL(au)
+	if err != nil {
+		p.badAggregator.error("Cannot determine base of %s: %v", aggURL, err)
+		return agg
+	}
+
+	p.checkAdjacentProviderMetadata(ctx, base)
+
+	parties := listedParties(agg)
+	p.checkIssuingParties(parties)
+
+	mirroring := agg.Aggregator != nil &&
+		agg.Aggregator.Category != nil &&
+		*agg.Aggregator.Category == csaf.AggregatorAggregator
+
+	if mirroring {
+		p.badMirrors.use()
+	}
+
+	for _, party := range parties {
+		p.checkListedParty(ctx, base, party, mirroring)
+	}
+
+	if err := p.checkHTTPCaching(ctx, aggURL); err != nil {
+		p.badHTTPCaching.error("Checking HTTP caching failed: %v", err)
+	}
+
+	return agg
+}
+
+// loadAggregator fetches the aggregator.json from the given URL
+// and validates it against the JSON schema.
+func (p *processor) loadAggregator(ctx context.Context, aggURL string) *csaf.Aggregator {
+	p.badAggregator.use()
+	p.checkTLS(aggURL)
+
+	res, err := p.httpClient().GetWithContext(ctx, aggURL)
+	if err != nil {
+		p.badAggregator.error("Fetching %s failed: %v", aggURL, err)
+		return nil
+	}
+	var doc any
+	if err := func() error {
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			return fmt.Errorf("status code %d (%s)", res.StatusCode, res.Status)
+		}
+		return misc.StrictJSONParse(res.Body, &doc)
+	}(); err != nil {
+		p.badAggregator.error("Loading %s failed: %v", aggURL, err)
+		return nil
+	}
+
+	errors, err := csaf.ValidateAggregator(doc)
+	if err != nil {
+		p.badAggregator.error("Validating %s failed: %v", aggURL, err)
+		return nil
+	}
+	if len(errors) > 0 {
+		p.badAggregator.error("%s: Validating against JSON schema failed:", aggURL)
+		for _, msg := range errors {
+			p.badAggregator.error("%s", strings.ReplaceAll(msg, `%`, `%%`))
+		}
+	}
+
+	var agg csaf.Aggregator
+	if err := util.ReMarshalJSON(&agg, doc); err != nil {
+		p.badAggregator.error("Cannot decode %s: %v", aggURL, err)
+		return nil
+	}
+	if err := agg.Validate(); err != nil {
+		p.badAggregator.error("%s is invalid: %v", aggURL, err)
+	}
+	return &agg
+}
+
+// checkAdjacentProviderMetadata checks that there is no
+// provider-metadata.json next to the aggregator.json.
+func (p *processor) checkAdjacentProviderMetadata(ctx context.Context, base string) {
+	pmdURL := base + "/provider-metadata.json"
+	res, err := p.httpClient().GetWithContext(ctx, pmdURL)
+	if err != nil {
+		p.badAggregator.warn("Cannot check for %s: %v", pmdURL, err)
+		return
+	}
+	res.Body.Close()
+	if res.StatusCode == http.StatusOK {
+		p.badAggregator.error(
+			"aggregator.json must not be adjacent to a provider-metadata.json, but %s exists.",
+			pmdURL)
+	}
+}
+
+// checkIssuingParties checks that at least two disjoint
+// issuing parties are listed.
+func (p *processor) checkIssuingParties(parties []*listedParty) {
+	p.badIssuingParties.use()
+	namespaces := util.Set[string]{}
+	for _, party := range parties {
+		if ns := party.namespace(); ns != "" {
+			namespaces.Add(ns)
+		} else {
+			p.badIssuingParties.warn("Listed %s has no publisher namespace.", party)
+		}
+	}
+	if n := len(namespaces); n < 2 {
+		p.badIssuingParties.error(
+			"aggregator.json lists %d disjoint issuing parties, at least 2 are required.", n)
+	} else {
+		p.badIssuingParties.info("aggregator.json lists %d disjoint issuing parties.", n)
+	}
+}
+
+// loadListedProviderMetadata loads a provider-metadata.json referenced
+// in an aggregator.json. Problems are reported to msgs.
+func (p *processor) loadListedProviderMetadata(
+	ctx context.Context,
+	u string,
+	msgs *topicMessages,
+) *csaf.LoadedProviderMetadata {
+	p.checkTLS(u)
+	loader := csaf.NewProviderMetadataLoader(p.httpClient())
+	lpmd := loader.LoadWithContext(ctx, u)
+	if !lpmd.Valid() {
+		msgs.error("Cannot load valid provider-metadata.json from %s.", u)
+		for i := range lpmd.Messages {
+			msgs.error("%s: %s", u, lpmd.Messages[i].Message)
+		}
+		return nil
+	}
+	if lpmd.URL != u {
+		msgs.warn("provider-metadata.json %s was loaded from %s.", u, lpmd.URL)
+	}
+	return lpmd
+}
+
+// checkListedParty checks the provider-metadata.json of a listed
+// provider or publisher, its public OpenPGP keys and its mirrors.
+func (p *processor) checkListedParty(
+	ctx context.Context,
+	base string,
+	party *listedParty,
+	mirroring bool,
+) {
+	md := party.metadata
+	if md == nil || md.URL == nil {
+		p.badAggregator.error("Listed %s has no metadata URL.", party)
+		return
+	}
+	pmdURL := string(*md.URL)
+
+	orig := p.loadListedProviderMetadata(ctx, pmdURL, &p.badAggregator)
+	if orig == nil {
+		return
+	}
+
+	var pmd csaf.ProviderMetadata
+	if err := util.ReMarshalJSON(&pmd, orig.Document); err != nil {
+		p.badAggregator.error("Cannot decode %s: %v", pmdURL, err)
+		return
+	}
+
+	switch {
+	case pmd.Publisher == nil || pmd.Publisher.Namespace == nil:
+		p.badAggregator.error("%s has no publisher namespace.", pmdURL)
+	case *pmd.Publisher.Namespace != party.namespace():
+		p.badAggregator.error(
+			"Namespace %q of listed %s does not match %q in %s.",
+			party.namespace(), party, *pmd.Publisher.Namespace, pmdURL)
+	}
+	if md.Role != nil && pmd.Role != nil && *md.Role != *pmd.Role {
+		p.badAggregator.warn("Role %q of listed %s does not match %q in %s.",
+			*md.Role, party, *pmd.Role, pmdURL)
+	}
+	if md.LastUpdated != nil && pmd.LastUpdated != nil {
+		if listed, current := time.Time(*md.LastUpdated), time.Time(*pmd.LastUpdated); listed.Before(current) {
+			p.badAggregator.warn("Listed %s was last updated %s, but %s was updated %s.",
+				party, listed.Format(time.RFC3339), pmdURL, current.Format(time.RFC3339))
+		}
+	}
+
+	keys := p.loadProviderKeys(ctx, pmdURL, pmd.PGPKeys, &p.badAggregator)
+
+	if !mirroring {
+		if len(party.mirrors) > 0 {
+			p.badAggregator.warn("Lister must not list mirrors, but %s has %d.",
+				party, len(party.mirrors))
+		}
+		return
+	}
+
+	if len(party.mirrors) == 0 {
+		p.badMirrors.warn("Listed %s is not mirrored.", party)
+		return
+	}
+	for _, mirror := range party.mirrors {
+		p.checkMirror(ctx, base, party, orig, keys, string(mirror))
+	}
+}
+
+// loadProviderKeys fetches the public OpenPGP keys listed in a
+// provider-metadata.json and compares their fingerprints.
+// Problems are reported to msgs.
+func (p *processor) loadProviderKeys(
+	ctx context.Context,
+	pmdURL string,
+	pgpKeys []csaf.PGPKey,
+	msgs *topicMessages,
+) *crypto.KeyRing {
+	if len(pgpKeys) == 0 {
+		msgs.warn("%s lists no public OpenPGP keys.", pmdURL)
+		return nil
+	}
+	client := p.httpClient()
+	var keys *crypto.KeyRing
+	for i := range pgpKeys {
+		key := &pgpKeys[i]
+		if key.URL == nil {
+			msgs.error("%s: Missing URL for fingerprint %x.", pmdURL, key.Fingerprint)
+			continue
+		}
+		u := *key.URL
+		p.checkTLS(u)
+		p.pgpKeyURLs.Add(u)
+		ckey, err := func() (*crypto.Key, error) {
+			res, err := client.GetWithContext(ctx, u)
+			if err != nil {
+				return nil, err
+			}
+			defer res.Body.Close()
+			if res.StatusCode != http.StatusOK {
+				return nil, fmt.Errorf("status code %d (%s)", res.StatusCode, res.Status)
+			}
+			return crypto.NewKeyFromArmoredReader(res.Body)
+		}()
+		if err != nil {
+			msgs.error("Loading public OpenPGP key %s failed: %v", u, err)
+			continue
+		}
+		if !strings.EqualFold(ckey.GetFingerprint(), string(key.Fingerprint)) {
+			msgs.error("Given fingerprint (%q) of public OpenPGP key %q "+
+				"does not match remotely loaded (%q).",
+				string(key.Fingerprint), u, ckey.GetFingerprint())
+			continue
+		}
+		if keys == nil {
+			if keys, err = crypto.NewKeyRing(ckey); err != nil {
+				msgs.error("Creating store for public OpenPGP key %s failed: %v.", u, err)
+			}
+		} else if err := keys.AddKey(ckey); err != nil {
+			msgs.error("Adding public OpenPGP key %s failed: %v.", u, err)
+		}
+	}
+	return keys
+}
+
+// listAdvisories lists the advisories of a provider in the
+// configured time range.
+func (p *processor) listAdvisories(
+	ctx context.Context,
+	lpmd *csaf.LoadedProviderMetadata,
+) ([]csaf.AdvisoryFile, error) {
+	base, err := url.Parse(lpmd.URL)
+	if err != nil {
+		return nil, err
+	}
+	afp := csaf.NewAdvisoryFileProcessor(p.httpClient(), p.expr, lpmd.Document, base)
+	afp.StreamingROLIEParser = p.cfg.StreamingROLIEParser
+	if p.cfg.Range != nil {
+		afp.AgeAccept = p.cfg.Range.Contains
+	}
+	var files []csaf.AdvisoryFile
+	if err := afp.ProcessWithContext(ctx, func(_ csaf.TLPLabel, fs []csaf.AdvisoryFile) error {
+		for _, f := range fs {
+			if !p.cfg.ignoreURL(f.URL()) {
+				files = append(files, f)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// fetchAdvisory downloads an advisory and returns its raw data
+// and its parsed JSON document.
+func (p *processor) fetchAdvisory(ctx context.Context, u string) ([]byte, any, error) {
+	p.checkTLS(u)
+	res, err := p.httpClient().GetWithContext(ctx, u)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("status code %d (%s)", res.StatusCode, res.Status)
+	}
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	var doc any
+	if err := misc.StrictJSONParse(bytes.NewReader(data), &doc); err != nil {
+		return nil, nil, err
+	}
+	return data, doc, nil
+}
+
+// fetchSignature downloads a detached signature.
+func (p *processor) fetchSignature(ctx context.Context, u string) (*crypto.PGPSignature, error) {
+	p.checkTLS(u)
+	res, err := p.httpClient().GetWithContext(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code %d (%s)", res.StatusCode, res.Status)
+	}
+	all, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.NewPGPSignatureFromArmored(string(all))
+}
+
+// revision extracts the version and the current release date of an advisory.
+func (p *processor) revision(doc any) string {
+	var version, date string
+	if v, err := p.expr.Eval(`$.document.tracking.version`, doc); err == nil {
+		version, _ = v.(string)
+	}
+	if d, err := p.expr.Eval(`$.document.tracking.current_release_date`, doc); err == nil {
+		date, _ = d.(string)
+	}
+	return "version " + version + " from " + date
+}
+
+// checkMirror compares the advisories of a mirror with
+// the ones of the original provider.
+func (p *processor) checkMirror(
+	ctx context.Context,
+	base string,
+	party *listedParty,
+	orig *csaf.LoadedProviderMetadata,
+	origKeys *crypto.KeyRing,
+	mirrorURL string,
+) {
+	// The mirror has to be in a folder adjacent to the aggregator.json.
+	if rest, ok := strings.CutPrefix(mirrorURL, base+"/"); !ok ||
+		strings.Count(strings.TrimSuffix(rest, "/provider-metadata.json"), "/") != 0 {
+		p.badMirrors.error("Mirror %s of %s is not in a folder adjacent to the aggregator.json.",
+			mirrorURL, party)
+	}
+
+	mirror := p.loadListedProviderMetadata(ctx, mirrorURL, &p.badMirrors)
+	if mirror == nil {
+		return
+	}
+
+	var pmd csaf.ProviderMetadata
+	if err := util.ReMarshalJSON(&pmd, mirror.Document); err != nil {
+		p.badMirrors.error("Cannot decode %s: %v", mirrorURL, err)
+		return
+	}
+	hasROLIE := false
+	for i := range pmd.Distributions {
+		if dist := &pmd.Distributions[i]; dist.Rolie != nil && len(dist.Rolie.Feeds) > 0 {
+			hasROLIE = true
+			break
+		}
+	}
+	if !hasROLIE {
+		p.badMirrors.error("Mirror %s of %s provides no ROLIE feeds.", mirrorURL, party)
+	}
+
+	mirrorKeys := p.loadProviderKeys(ctx, mirrorURL, pmd.PGPKeys, &p.badMirrors)
+
+	origFiles, err := p.listAdvisories(ctx, orig)
+	if err != nil {
+		p.badMirrors.error("Listing advisories of %s failed: %v", party, err)
+		return
+	}
+	mirrorFiles, err := p.listAdvisories(ctx, mirror)
+	if err != nil {
+		p.badMirrors.error("Listing advisories of mirror %s failed: %v", mirrorURL, err)
+		return
+	}
+
+	mirrored := make(map[string]csaf.AdvisoryFile, len(mirrorFiles))
+	for _, f := range mirrorFiles {
+		mirrored[path.Base(f.URL())] = f
+	}
+
+	sort.Slice(origFiles, func(i, j int) bool {
+		return origFiles[i].URL() < origFiles[j].URL()
+	})
+
+	var missing, stale []string
+	for _, of := range origFiles {
+		name := path.Base(of.URL())
+		mf, ok := mirrored[name]
+		if !ok {
+			missing = append(missing, of.URL())
+			continue
+		}
+		delete(mirrored, name)
+		if s := p.compareMirrored(ctx, of, mf, origKeys, mirrorKeys); s != "" {
+			stale = append(stale, s)
+		}
+	}
+
+	if len(missing) > 0 {
+		p.badMirrors.error("Mirror %s of %s misses %d of %d advisories: %s",
+			mirrorURL, party, len(missing), len(origFiles), listURLs(missing))
+	}
+	if len(stale) > 0 {
+		p.badMirrors.error("Mirror %s of %s has %d stale advisories: %s",
+			mirrorURL, party, len(stale), listURLs(stale))
+	}
+	if len(mirrored) > 0 {
+		extra := make([]string, 0, len(mirrored))
+		for _, f := range mirrored {
+			extra = append(extra, f.URL())
+		}
+		sort.Strings(extra)
+		p.badMirrors.warn("Mirror %s of %s has %d advisories not found at the original: %s",
+			mirrorURL, party, len(extra), listURLs(extra))
+	}
+	if len(missing) == 0 && len(stale) == 0 {
+		p.badMirrors.info("Mirror %s of %s is up to date (%d advisories compared).",
+			mirrorURL, party, len(origFiles))
+	}
+}
+
+// compareMirrored compares a mirrored advisory with its original.
+// It returns a description if the mirrored advisory is stale.
+func (p *processor) compareMirrored(
+	ctx context.Context,
+	orig, mirror csaf.AdvisoryFile,
+	origKeys, mirrorKeys *crypto.KeyRing,
+) string {
+	u := mirror.URL()
+	mdata, mdoc, err := p.fetchAdvisory(ctx, u)
+	if err != nil {
+		p.badMirrors.error("Fetching mirrored advisory %s failed: %v", u, err)
+		return ""
+	}
+
+	p.invalidAdvisories.use()
+	if errors, err := csaf.ValidateCSAF(mdoc); err != nil {
+		p.invalidAdvisories.error("Failed to validate %s: %v", u, err)
+	} else if len(errors) > 0 {
+		p.invalidAdvisories.error("CSAF file %s has %d validation errors.", u, len(errors))
+	}
+	p.badFilenames.use()
+	if err := util.IDMatchesFilename(p.expr, mdoc, path.Base(u)); err != nil {
+		p.badFilenames.error("%s: %v", u, err)
+	}
+
+	odata, odoc, err := p.fetchAdvisory(ctx, orig.URL())
+	if err != nil {
+		p.badMirrors.error("Fetching original advisory %s failed: %v", orig.URL(), err)
+		return ""
+	}
+
+	p.checkMirroredSignature(ctx, mirror, mdata, origKeys, mirrorKeys)
+
+	if sha256.Sum256(mdata) == sha256.Sum256(odata) {
+		return ""
+	}
+	if mrev, orev := p.revision(mdoc), p.revision(odoc); mrev != orev {
+		return fmt.Sprintf("%s (%s, original has %s)", u, mrev, orev)
+	}
+	return fmt.Sprintf("%s (content differs from %s)", u, orig.URL())
+}
+
+// checkMirroredSignature checks if the signature of a mirrored advisory
+// can be verified with the keys of the original provider or at least
+// with the keys of the mirror.
+func (p *processor) checkMirroredSignature(
+	ctx context.Context,
+	mirror csaf.AdvisoryFile,
+	data []byte,
+	origKeys, mirrorKeys *crypto.KeyRing,
+) {
+	signURL := mirror.SignURL()
+	if signURL == "" {
+		p.badMirrors.error("Mirrored advisory %s has no signature.", mirror.URL())
+		return
+	}
+	sig, err := p.fetchSignature(ctx, signURL)
+	if err != nil {
+		p.badMirrors.error("Loading signature %s failed: %v", signURL, err)
+		return
+	}
+	pm := crypto.NewPlainMessage(data)
+	t := crypto.GetUnixTime()
+	for _, keys := range []*crypto.KeyRing{origKeys, mirrorKeys} {
+		if keys != nil && keys.VerifyDetached(pm, sig, t) == nil {
+			return
+		}
+	}
+	p.badMirrors.error("Signature %s of mirrored advisory could not be verified.", signURL)
+}
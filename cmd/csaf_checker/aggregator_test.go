@@ -0,0 +1,166 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gocsaf/csaf/v3/internal/testutil"
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+// aggregatorTemplate is an aggregator.json listing the test provider.
+const aggregatorTemplate = `{
+  "aggregator": {
+    "category": %q,
+    "contact_details": "mailto:aggregator@example.com",
+    "name": "Test aggregator",
+    "namespace": "https://aggregator.example.com"
+  },
+  "aggregator_version": "2.0",
+  "canonical_url": "%[2]s/aggregator/aggregator.json",
+  "csaf_providers": [
+    {
+      "metadata": {
+        "last_updated": "2020-01-01T00:00:00Z",
+        "publisher": {
+          "category": "vendor",
+          "name": "ACME Inc",
+          "namespace": "https://example.com",
+          "contact_details": "mailto:security@example.com"
+        },
+        "role": "csaf_trusted_provider",
+        "url": "%[2]s/provider-metadata.json"
+      }%[3]s
+    }
+  ],
+  "last_updated": "2020-01-01T00:00:00Z"
+}`
+
+func TestCheckAggregator(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		category string
+		mirror   bool
+		discover bool
+		want     map[int][]string
+	}{
+		{
+			name:     "lister",
+			category: "lister",
+			want: map[int][]string{
+				21: {"INFO:listed provider-metadata.json files are valid"},
+				22: {"ERROR:1 disjoint issuing parties"},
+			},
+		},
+		{
+			name:     "aggregator",
+			category: "aggregator",
+			mirror:   true,
+			want: map[int][]string{
+				21: {"INFO:listed provider-metadata.json files are valid"},
+				22: {"ERROR:1 disjoint issuing parties"},
+				23: {
+					"ERROR:not in a folder adjacent to the aggregator.json",
+					"INFO:is up to date (1 advisories compared)",
+				},
+			},
+		},
+		{
+			name:     "discovered",
+			category: "lister",
+			discover: true,
+			want: map[int][]string{
+				21: {"INFO:listed provider-metadata.json files are valid"},
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			params := testutil.ProviderParams{
+				EnableSha256: true,
+				EnableSha512: true,
+			}
+			provider := testutil.ProviderHandler(&params, false)
+			var serverURL string
+			aggPath := "/aggregator/aggregator.json"
+			if test.discover {
+				aggPath = "/.well-known/csaf-aggregator/aggregator.json"
+			}
+			mux := http.NewServeMux()
+			// Only the aggregator.json should be discoverable.
+			mux.Handle("/security.txt", http.NotFoundHandler())
+			mux.HandleFunc(aggPath, func(w http.ResponseWriter, _ *http.Request) {
+				var mirrors string
+				if test.mirror {
+					mirrors = fmt.Sprintf(`, "mirrors": [ %q ]`, serverURL+"/provider-metadata.json")
+				}
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(w, aggregatorTemplate, test.category, serverURL, mirrors)
+			})
+			mux.Handle("/", provider)
+
+			server := httptest.NewTLSServer(mux)
+			defer server.Close()
+			serverURL = server.URL
+			params.URL = server.URL
+
+			cfg := config{}
+			if err := cfg.prepare(); err != nil {
+				t.Fatal(err)
+			}
+			p, err := newProcessor(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer p.close()
+			p.client = &util.BasicClient{Client: server.Client()}
+
+			name := serverURL + aggPath
+			if test.discover {
+				name = strings.TrimPrefix(serverURL, "https://")
+			}
+			report, err := p.run(context.Background(), []string{name})
+			if err != nil {
+				t.Fatal(err)
+			}
+			domain := report.Domains[0]
+			if domain.Name != name {
+				t.Errorf("got domain name %q, want %q", domain.Name, name)
+			}
+			if domain.Aggregator == nil || string(*domain.Aggregator.Category) != test.category {
+				t.Fatalf("aggregator info not filled: %v", domain.Aggregator)
+			}
+
+			for _, req := range domain.Requirements {
+				want, ok := test.want[req.Num]
+				if !ok {
+					continue
+				}
+				if len(req.Messages) != len(want) {
+					t.Errorf("requirement %d: got %v, want %v", req.Num, req.Messages, want)
+					continue
+				}
+				for i, w := range want {
+					typ, text, _ := strings.Cut(w, ":")
+					if got := req.Messages[i]; got.Type.String() != typ || !strings.Contains(got.Text, text) {
+						t.Errorf("requirement %d, message %d: got %v, want %s", req.Num, i, got, w)
+					}
+				}
+			}
+			if domain.Passed {
+				t.Error("aggregator with only one issuing party should not pass")
+			}
+		})
+	}
+}
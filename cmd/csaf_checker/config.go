@@ -11,12 +11,15 @@ package main
 import (
 	"crypto/tls"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/gocsaf/csaf/v3/internal/certs"
 	"github.com/gocsaf/csaf/v3/internal/filter"
 	"github.com/gocsaf/csaf/v3/internal/models"
 	"github.com/gocsaf/csaf/v3/internal/options"
+	"github.com/gocsaf/csaf/v3/util"
 )
 
 type outputFormat string
@@ -24,6 +27,14 @@ type outputFormat string
 const (
 	defaultPreset = "mandatory"
 	defaultFormat = "json"
+	// defaultLogFormat is the default format of the log records.
+	defaultLogFormat = options.LogFormatText
+	// defaultCertExpiryWindow is the default time span before
+	// the expiry of a server certificate to warn about it.
+	defaultCertExpiryWindow = 30 * 24 * time.Hour
+	// defaultKeyExpiryWindow is the default time span before
+	// the expiry of a public OpenPGP key to warn about it.
+	defaultKeyExpiryWindow = 30 * 24 * time.Hour
 )
 
 type config struct {
@@ -37,17 +48,32 @@ type config struct {
 	Version                bool              `long:"version" description:"Display version of the binary" toml:"-"`
 	Verbose                bool              `long:"verbose" short:"v" description:"Verbose output" toml:"verbose"`
 	Rate                   *float64          `long:"rate" short:"r" description:"The average upper limit of https operations per second (defaults to unlimited)" toml:"rate"`
+	Retries                *int              `long:"retries" description:"Retry failed GET and HEAD requests up to NUM times (defaults to 3)" value-name:"NUM" toml:"retries"`
+	RetryDelay             time.Duration     `long:"retry_delay" description:"Wait DURATION before the first retry, doubled with every further retry (defaults to 1s)" value-name:"DURATION" toml:"retry_delay"`
+	RetryMaxDelay          time.Duration     `long:"retry_max_delay" description:"Wait at most DURATION between retries (defaults to 1m)" value-name:"DURATION" toml:"retry_max_delay"`
+	Proxy                  string            `long:"proxy" description:"Send the requests through the proxy at URL (http, https or socks5, 'direct' for none, defaults to the environment)" value-name:"URL" toml:"proxy"`
+	Proxies                map[string]string `toml:"proxies"`
+	CacheDir               string            `long:"cache_dir" description:"Cache HTTP responses in DIR to speed up repeated runs" value-name:"DIR" toml:"cache_dir"`
 	Range                  *models.TimeRange `long:"time_range" short:"t" description:"RANGE of time from which advisories to download" value-name:"RANGE" toml:"time_range"`
 	IgnorePattern          []string          `long:"ignore_pattern" short:"i" description:"Do not download files if their URLs match any of the given PATTERNs" value-name:"PATTERN" toml:"ignore_pattern"`
 	ExtraHeader            http.Header       `long:"header" short:"H" description:"One or more extra HTTP header fields" toml:"header"`
 	RemoteValidator        string            `long:"validator" description:"URL to validate documents remotely" value-name:"URL" toml:"validator"`
 	RemoteValidatorCache   string            `long:"validator_cache" description:"FILE to cache remote validations" value-name:"FILE" toml:"validator_cache"`
 	RemoteValidatorPresets []string          `long:"validator_preset" description:"One or more presets to validate remotely" toml:"validator_preset"`
+	CertExpiryWindow       time.Duration     `long:"cert_expiry_window" description:"Warn if server certificates expire within DURATION" value-name:"DURATION" toml:"cert_expiry_window"`
+	KeyExpiryWindow        time.Duration     `long:"key_expiry_window" description:"Warn if public OpenPGP keys expire within DURATION" value-name:"DURATION" toml:"key_expiry_window"`
+
+	LogFile string `long:"log_file" description:"FILE to log to (defaults to STDERR)" value-name:"FILE" toml:"log_file"`
+	//lint:ignore SA5008 We are using choice or than once: debug, info, warn, error
+	LogLevel *options.LogLevel `long:"log_level" description:"LEVEL of logging details (defaults to info, debug if verbose)" value-name:"LEVEL" choice:"debug" choice:"info" choice:"warn" choice:"error" toml:"log_level"`
+	//lint:ignore SA5008 We are using choice twice: text, json
+	LogFormat options.LogFormat `long:"log_format" description:"FORMAT of the log records" value-name:"FORMAT" choice:"text" choice:"json" toml:"log_format"`
 
 	Config string `short:"c" long:"config" description:"Path to config TOML file" value-name:"TOML-FILE" toml:"-"`
 
 	clientCerts          []tls.Certificate
 	ignorePattern        filter.PatternMatcher
+	proxies              *util.Proxies
 	StreamingROLIEParser bool `long:"streaming_rolie_parser" description:"Use the streaming ROLIE feed parser (experimental)" toml:"streaming_rolie_parser"`
 }
 
@@ -83,6 +109,9 @@ func parseArgsConfig() ([]string, *config, error) {
 		SetDefaults: func(cfg *config) {
 			cfg.Format = defaultFormat
 			cfg.RemoteValidatorPresets = []string{defaultPreset}
+			cfg.CertExpiryWindow = defaultCertExpiryWindow
+			cfg.KeyExpiryWindow = defaultKeyExpiryWindow
+			cfg.LogFormat = defaultLogFormat
 		},
 		// Re-establish default values if not set.
 		EnsureDefaults: func(cfg *config) {
@@ -92,6 +121,15 @@ func parseArgsConfig() ([]string, *config, error) {
 			if cfg.RemoteValidatorPresets == nil {
 				cfg.RemoteValidatorPresets = []string{defaultPreset}
 			}
+			if cfg.CertExpiryWindow == 0 {
+				cfg.CertExpiryWindow = defaultCertExpiryWindow
+			}
+			if cfg.KeyExpiryWindow == 0 {
+				cfg.KeyExpiryWindow = defaultKeyExpiryWindow
+			}
+			if cfg.LogFormat == "" {
+				cfg.LogFormat = defaultLogFormat
+			}
 		},
 	}
 	return p.Parse()
@@ -113,13 +151,37 @@ func (cfg *config) ignoreURL(u string) bool {
 // prepare prepares internal state of a loaded configuration.
 func (cfg *config) prepare() error {
 
+	if err := cfg.prepareLogging(); err != nil {
+		return err
+	}
+
 	// Pre-compile the regexes used to check if we need to ignore advisories.
 	if err := cfg.compileIgnorePatterns(); err != nil {
 		return err
 	}
 
 	// Load client certs.
-	return cfg.prepareCertificates()
+	if err := cfg.prepareCertificates(); err != nil {
+		return err
+	}
+
+	return cfg.prepareProxies()
+}
+
+// prepareLogging sets up the structured logging.
+func (cfg *config) prepareLogging() error {
+	lg := options.Logging{
+		Format: cfg.LogFormat,
+		Level:  slog.LevelInfo,
+		File:   cfg.LogFile,
+	}
+	switch {
+	case cfg.LogLevel != nil:
+		lg.Level = cfg.LogLevel.Level
+	case cfg.Verbose:
+		lg.Level = slog.LevelDebug
+	}
+	return lg.SetDefault()
 }
 
 // compileIgnorePatterns compiles the configure patterns to be ignored.
@@ -142,3 +204,57 @@ func (cfg *config) prepareCertificates() error {
 	cfg.clientCerts = cert
 	return nil
 }
+
+// prepareProxies sets up the proxies if configured.
+func (cfg *config) prepareProxies() error {
+	if cfg.Proxy == "" && len(cfg.Proxies) == 0 {
+		return nil
+	}
+	proxies, err := util.NewProxies(cfg.Proxy, cfg.Proxies)
+	if err != nil {
+		return err
+	}
+	cfg.proxies = proxies
+	return nil
+}
+
+// retryingClient adds retries of failed requests to a client if configured.
+func (cfg *config) retryingClient(cwc util.ClientWithContext) util.ClientWithContext {
+	retries := util.DefaultRetries
+	if cfg.Retries != nil {
+		retries = *cfg.Retries
+	}
+	if retries <= 0 {
+		return cwc
+	}
+	rc := &util.RetryingClient{
+		Client:   cwc,
+		Retries:  retries,
+		Delay:    cfg.RetryDelay,
+		MaxDelay: cfg.RetryMaxDelay,
+	}
+	rc.Log = func(method, url string, attempt int, wait time.Duration, reason string) {
+		slog.Debug("Retrying request",
+			"method", method,
+			"url", url,
+			"attempt", attempt,
+			"wait", wait,
+			"reason", reason)
+	}
+	return rc
+}
+
+// cachingClient adds the caching of responses to a client if configured.
+func (cfg *config) cachingClient(cwc util.ClientWithContext) util.ClientWithContext {
+	if cfg.CacheDir == "" {
+		return cwc
+	}
+	// Always contact the servers as the TLS and redirect
+	// checks need to see the connections.
+	return &util.CachingClient{
+		Client:      cwc,
+		Dir:         cfg.CacheDir,
+		Revalidate:  true,
+		Credentials: cfg.protectedAccess(),
+	}
+}
@@ -0,0 +1,252 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+// maxListedURLs is the maximal number of URLs listed
+// as examples in a single message.
+const maxListedURLs = 5
+
+// resourceKind classifies the fetched resources by their
+// expected content type.
+type resourceKind int
+
+const (
+	jsonResource resourceKind = iota
+	signatureResource
+	publicKeyResource
+	hashResource
+)
+
+// resourceKinds are all kinds of resources which are inspected.
+var resourceKinds = []resourceKind{
+	jsonResource,
+	signatureResource,
+	publicKeyResource,
+	hashResource,
+}
+
+// String implements [fmt.Stringer].
+func (rk resourceKind) String() string {
+	switch rk {
+	case jsonResource:
+		return "JSON (advisories, provider metadata, ROLIE)"
+	case signatureResource:
+		return "signature (.asc)"
+	case publicKeyResource:
+		return "public OpenPGP key"
+	case hashResource:
+		return "hash (.sha256, .sha512)"
+	default:
+		return "unknown"
+	}
+}
+
+// contentType returns the expected content type of a kind of resource.
+func (rk resourceKind) contentType() string {
+	switch rk {
+	case jsonResource:
+		return "application/json"
+	case signatureResource:
+		return "application/pgp-signature"
+	case publicKeyResource:
+		return "application/pgp-keys"
+	default:
+		return "text/plain"
+	}
+}
+
+// cacheSample is a resource which is used to test
+// if conditional requests are supported.
+type cacheSample struct {
+	url          string
+	etag         string
+	lastModified string
+}
+
+// httpCacheStats collects the findings for a kind of resource.
+type httpCacheStats struct {
+	total        int
+	badTypes     []string
+	noValidators []string
+	sample       *cacheSample
+}
+
+// inspectingTransport is a [http.RoundTripper] which passes
+// the connections it sees to the processor for inspection.
+type inspectingTransport struct {
+	http.RoundTripper
+	p *processor
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (it *inspectingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := it.RoundTripper.RoundTrip(req)
+	switch {
+	case err != nil:
+		it.p.checkTLSFailure(req.URL.Hostname(), err)
+	case res.TLS != nil:
+		it.p.checkTLSConnection(req.URL.Hostname(), res)
+	}
+	return res, err
+}
+
+// recordingClient passes the final responses of the fully
+// configured client to the processor to record their content
+// types and validators. It sits on top of the response cache
+// so that revalidated responses are recorded, too.
+type recordingClient struct {
+	util.ClientWithContext
+	p *processor
+}
+
+// Do implements the respective method of the [util.Client] interface.
+func (rc *recordingClient) Do(req *http.Request) (*http.Response, error) {
+	res, err := rc.ClientWithContext.Do(req)
+	if err == nil {
+		rc.p.recordHTTPCaching(req, res)
+	}
+	return res, err
+}
+
+// GetWithContext implements the respective method of the [util.ClientWithContext] interface.
+func (rc *recordingClient) GetWithContext(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return rc.Do(req)
+}
+
+// Get implements the respective method of the [util.Client] interface.
+func (rc *recordingClient) Get(url string) (*http.Response, error) {
+	return rc.GetWithContext(context.Background(), url)
+}
+
+// classifyResource determines the kind of resource by its URL.
+// The boolean is false if the resource is not of interest.
+func (p *processor) classifyResource(u string) (resourceKind, bool) {
+	lower := strings.ToLower(u)
+	switch {
+	case p.pgpKeyURLs.Contains(u):
+		return publicKeyResource, true
+	case strings.HasSuffix(lower, ".json"):
+		return jsonResource, true
+	case strings.HasSuffix(lower, ".asc"):
+		return signatureResource, true
+	case strings.HasSuffix(lower, ".sha256"), strings.HasSuffix(lower, ".sha512"):
+		return hashResource, true
+	}
+	return 0, false
+}
+
+// recordHTTPCaching records the content type and the validators
+// of successfully fetched resources.
+func (p *processor) recordHTTPCaching(req *http.Request, res *http.Response) {
+	if req.Method != http.MethodGet || res.StatusCode != http.StatusOK {
+		return
+	}
+	u := req.URL.String()
+	kind, ok := p.classifyResource(u)
+	if !ok {
+		return
+	}
+	stats := p.httpCaching[kind]
+	if stats == nil {
+		stats = new(httpCacheStats)
+		p.httpCaching[kind] = stats
+	}
+	stats.total++
+
+	ct := res.Header.Get("Content-Type")
+	if mt, _, err := mime.ParseMediaType(ct); err != nil || mt != kind.contentType() {
+		stats.badTypes = append(stats.badTypes, u+" ("+ct+")")
+	}
+
+	etag, lastModified := res.Header.Get("ETag"), res.Header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		stats.noValidators = append(stats.noValidators, u)
+	} else if stats.sample == nil {
+		stats.sample = &cacheSample{
+			url:          u,
+			etag:         etag,
+			lastModified: lastModified,
+		}
+	}
+}
+
+// listURLs joins the first few of the given URLs for a message.
+func listURLs(urls []string) string {
+	if len(urls) <= maxListedURLs {
+		return strings.Join(urls, ", ")
+	}
+	return strings.Join(urls[:maxListedURLs], ", ") + ", ..."
+}
+
+// checkHTTPCaching evaluates the recorded content types and validators
+// and tests with a sample of each kind of resources if conditional
+// requests are answered with "304 Not Modified".
+func (p *processor) checkHTTPCaching(ctx context.Context, _ string) error {
+	p.badHTTPCaching.use()
+
+	client := p.httpClient()
+
+	for _, kind := range resourceKinds {
+		stats := p.httpCaching[kind]
+		if stats == nil {
+			continue
+		}
+		if n := len(stats.badTypes); n > 0 {
+			p.badHTTPCaching.warn(
+				"%d of %d %s resources are not served as '%s': %s",
+				n, stats.total, kind, kind.contentType(), listURLs(stats.badTypes))
+		}
+		if n := len(stats.noValidators); n > 0 {
+			p.badHTTPCaching.warn(
+				"%d of %d %s resources are served without ETag or Last-Modified header: %s",
+				n, stats.total, kind, listURLs(stats.noValidators))
+		}
+		if stats.sample == nil {
+			continue
+		}
+		sample := stats.sample
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, sample.url, nil)
+		if err != nil {
+			return err
+		}
+		if sample.etag != "" {
+			req.Header.Set("If-None-Match", sample.etag)
+		}
+		if sample.lastModified != "" {
+			req.Header.Set("If-Modified-Since", sample.lastModified)
+		}
+		res, err := client.Do(req)
+		if err != nil {
+			p.badHTTPCaching.warn("Conditional request for %s failed: %v", sample.url, err)
+			continue
+		}
+		io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+		if res.StatusCode != http.StatusNotModified {
+			p.badHTTPCaching.warn(
+				"Conditional request for unchanged %s was answered with status code %d (%s) instead of 304.",
+				sample.url, res.StatusCode, res.Status)
+		}
+	}
+	return nil
+}
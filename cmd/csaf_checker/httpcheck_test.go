@@ -0,0 +1,194 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckHTTPCaching(t *testing.T) {
+	modified := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	server := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/good.json":
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.Header().Set("ETag", `"v1"`)
+				http.ServeContent(w, r, "good.json", modified, bytes.NewReader([]byte("{}")))
+			case "/bad.json":
+				w.Header().Set("Content-Type", "text/plain")
+				w.Write([]byte("{}"))
+			case "/good.json.sha256":
+				w.Header().Set("Content-Type", "text/plain")
+				w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+				w.Write([]byte("00"))
+			case "/key.asc":
+				w.Header().Set("Content-Type", "application/pgp-keys")
+				w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+				w.Write([]byte("key"))
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+	defer server.Close()
+
+	p, err := newProcessor(&config{Insecure: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.close()
+	p.pgpKeyURLs.Add(server.URL + "/key.asc")
+
+	ctx := context.Background()
+	client := p.httpClient()
+	for _, path := range []string{
+		"/good.json", "/bad.json", "/good.json.sha256", "/key.asc", "/missing.json",
+	} {
+		res, err := client.GetWithContext(ctx, server.URL+path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+
+	if stats := p.httpCaching[jsonResource]; stats == nil || stats.total != 2 {
+		t.Fatalf("expected two recorded JSON resources, got %+v", stats)
+	}
+
+	if err := p.checkHTTPCaching(ctx, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"1 of 2 JSON (advisories, provider metadata, ROLIE) resources are not served as 'application/json'",
+		"1 of 2 JSON (advisories, provider metadata, ROLIE) resources are served without ETag or Last-Modified header",
+		"Conditional request for unchanged " + server.URL + "/key.asc",
+		"Conditional request for unchanged " + server.URL + "/good.json.sha256",
+	}
+	if len(p.badHTTPCaching) != len(want) {
+		t.Fatalf("got %d messages, want %d: %v", len(p.badHTTPCaching), len(want), p.badHTTPCaching)
+	}
+	for i, w := range want {
+		msg := p.badHTTPCaching[i]
+		if msg.Type != WarnType || !strings.HasPrefix(msg.Text, w) {
+			t.Errorf("message %d: got %v, want prefix %q", i, msg, w)
+		}
+	}
+}
+
+func TestInspectCachedResponses(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/moved.json":
+				http.Redirect(w, r, "/doc.json", http.StatusMovedPermanently)
+			case "/doc.json":
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Cache-Control", "max-age=3600")
+				w.Header().Set("ETag", `"v1"`)
+				if r.Header.Get("If-None-Match") == `"v1"` {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				w.Write([]byte("{}"))
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+	defer server.Close()
+
+	cfg := config{Insecure: true, CacheDir: t.TempDir()}
+
+	for run := range 2 {
+		p, err := newProcessor(&cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := p.httpClient().GetWithContext(
+			context.Background(), server.URL+"/moved.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The body has to be read completely to be cached.
+		_, err = io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+		p.close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("run %d: status %d", run, res.StatusCode)
+		}
+		if !p.badTLS.used() {
+			t.Errorf("run %d: TLS connection was not inspected", run)
+		}
+		if len(p.redirects[server.URL+"/doc.json"]) != 1 {
+			t.Errorf("run %d: redirect was not recorded: %v", run, p.redirects)
+		}
+		if stats := p.httpCaching[jsonResource]; stats == nil || stats.total != 1 {
+			t.Errorf("run %d: response was not recorded: %+v", run, stats)
+		}
+	}
+}
+
+func TestCacheProtectedAccess(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Api-Key") != "secret" {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Cache-Control", "max-age=3600")
+			w.Write([]byte("{}"))
+		}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	p, err := newProcessor(&config{
+		Insecure:    true,
+		CacheDir:    dir,
+		ExtraHeader: http.Header{"Api-Key": []string{"secret"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.close()
+
+	res, err := p.httpClient().GetWithContext(
+		context.Background(), server.URL+"/red.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", res.StatusCode)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("response fetched with credentials was cached: %d files", len(entries))
+	}
+}
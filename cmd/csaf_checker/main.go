@@ -11,7 +11,7 @@ package main
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 
@@ -38,15 +38,15 @@ func run(cfg *config, domains []string) (*Report, error) {
 func main() {
 	domains, cfg, err := parseArgsConfig()
 	options.ErrorCheck(err)
-	options.ErrorCheck(cfg.prepare())
+	options.ErrorCheckStructured(cfg.prepare())
 
 	if len(domains) == 0 {
-		log.Println("No domain or direct url given.")
+		slog.Warn("No domain or direct url given.")
 		return
 	}
 
 	report, err := run(cfg, domains)
-	options.ErrorCheck(err)
+	options.ErrorCheckStructured(err)
 
-	options.ErrorCheck(report.write(cfg.Format, cfg.Output))
+	options.ErrorCheckStructured(report.write(cfg.Format, cfg.Output))
 }
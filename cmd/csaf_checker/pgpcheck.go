@@ -0,0 +1,171 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+const (
+	// minPGPRSAKeySize is the minimal RSA key size in bits
+	// before a key is considered to be broken.
+	minPGPRSAKeySize = 2048
+	// recommendedPGPRSAKeySize is the RSA key size in bits
+	// below which a key is considered to be weak.
+	recommendedPGPRSAKeySize = 3072
+)
+
+// keyExpiry returns the expiry time of the given public key
+// regarding its self-signature. The boolean is false if
+// the key does not expire.
+func keyExpiry(pk *packet.PublicKey, sig *packet.Signature) (time.Time, bool) {
+	if sig == nil || sig.KeyLifetimeSecs == nil || *sig.KeyLifetimeSecs == 0 {
+		return time.Time{}, false
+	}
+	return pk.CreationTime.Add(time.Duration(*sig.KeyLifetimeSecs) * time.Second), true
+}
+
+// checkPGPKeyAlgorithm checks the algorithm and the size of the given key.
+func (p *processor) checkPGPKeyAlgorithm(u, kind string, pk *packet.PublicKey) {
+	switch pk.PubKeyAlgo {
+	case packet.PubKeyAlgoRSA, packet.PubKeyAlgoRSASignOnly, packet.PubKeyAlgoRSAEncryptOnly:
+		bits, err := pk.BitLength()
+		switch {
+		case err != nil:
+			p.badPGPs.error("Cannot determine size of %s %X of public OpenPGP key %s: %v",
+				kind, pk.KeyId, u, err)
+		case bits < minPGPRSAKeySize:
+			p.badPGPs.error("%s %X of public OpenPGP key %s is RSA with only %d bits.",
+				kind, pk.KeyId, u, bits)
+		case bits < recommendedPGPRSAKeySize:
+			p.badPGPs.warn("%s %X of public OpenPGP key %s is RSA with only %d bits (%d recommended).",
+				kind, pk.KeyId, u, bits, recommendedPGPRSAKeySize)
+		}
+	case packet.PubKeyAlgoDSA, packet.PubKeyAlgoElGamal:
+		p.badPGPs.error("%s %X of public OpenPGP key %s uses weak algorithm %s.",
+			kind, pk.KeyId, u, pgpAlgorithmName(pk.PubKeyAlgo))
+	}
+}
+
+// pgpAlgorithmName returns a readable name of the given algorithm.
+func pgpAlgorithmName(alg packet.PublicKeyAlgorithm) string {
+	switch alg {
+	case packet.PubKeyAlgoDSA:
+		return "DSA"
+	case packet.PubKeyAlgoElGamal:
+		return "ElGamal"
+	default:
+		return fmt.Sprintf("algorithm %d", alg)
+	}
+}
+
+// checkPGPKeyQuality checks if the given public OpenPGP key loaded from
+// the given URL is revoked, expired, soon to expire, uses weak
+// algorithms or is not capable of signing.
+func (p *processor) checkPGPKeyQuality(u string, key *crypto.Key) {
+	if key.IsRevoked() {
+		p.badPGPs.error("Public OpenPGP key %s is revoked.", u)
+	}
+
+	entity := key.GetEntity()
+	now := time.Now()
+
+	checkExpiry := func(kind string, pk *packet.PublicKey, sig *packet.Signature) {
+		expiry, ok := keyExpiry(pk, sig)
+		switch {
+		case !ok:
+		case now.After(expiry):
+			p.badPGPs.error("%s %X of public OpenPGP key %s expired on %s.",
+				kind, pk.KeyId, u, expiry.UTC().Format(time.RFC3339))
+		case now.Add(p.cfg.KeyExpiryWindow).After(expiry):
+			p.badPGPs.warn("%s %X of public OpenPGP key %s expires on %s.",
+				kind, pk.KeyId, u, expiry.UTC().Format(time.RFC3339))
+		}
+	}
+
+	// The primary key properties are either stored in the
+	// self-signature of the primary identity or in a direct-key signature.
+	selfSig := entity.SelfSignature
+	if identity := entity.PrimaryIdentity(); identity != nil && identity.SelfSignature != nil {
+		selfSig = identity.SelfSignature
+	}
+	checkExpiry("Primary key", entity.PrimaryKey, selfSig)
+	p.checkPGPKeyAlgorithm(u, "Primary key", entity.PrimaryKey)
+	p.keyIDs.Add(entity.PrimaryKey.KeyId)
+
+	for i := range entity.Subkeys {
+		sub := &entity.Subkeys[i]
+		p.keyIDs.Add(sub.PublicKey.KeyId)
+		// Only subkeys used for signing are of interest.
+		if sub.Sig == nil || !sub.Sig.FlagSign || sub.Revoked(now) {
+			continue
+		}
+		checkExpiry("Signing subkey", sub.PublicKey, sub.Sig)
+		p.checkPGPKeyAlgorithm(u, "Signing subkey", sub.PublicKey)
+	}
+
+	if !key.CanVerify() {
+		p.badPGPs.error("Public OpenPGP key %s has no valid key capable of signing.", u)
+	}
+}
+
+// recordUnloadedKey remembers that the public OpenPGP key with
+// the given fingerprint is listed in the provider-metadata.json
+// but could not be loaded.
+func (p *processor) recordUnloadedKey(fingerprint string, err error) {
+	// The key id is made of the lower 64 bits of a v4 fingerprint.
+	if len(fingerprint) < 16 {
+		return
+	}
+	id, perr := strconv.ParseUint(fingerprint[len(fingerprint)-16:], 16, 64)
+	if perr != nil {
+		return
+	}
+	p.unloadedKeys[id] = err
+}
+
+// unlistedSignatureKeys returns the ids of the keys which were
+// used to create the given signature but which are not
+// listed in the provider-metadata.json.
+func (p *processor) unlistedSignatureKeys(sig *crypto.PGPSignature) []string {
+	ids, ok := sig.GetSignatureKeyIDs()
+	if !ok {
+		return nil
+	}
+	var unlisted []string
+	for _, id := range ids {
+		if _, unloaded := p.unloadedKeys[id]; !unloaded && !p.keyIDs.Contains(id) {
+			unlisted = append(unlisted, fmt.Sprintf("%016X", id))
+		}
+	}
+	return unlisted
+}
+
+// unloadedSignatureKeys returns the ids of the keys which were used
+// to create the given signature and which are listed in the
+// provider-metadata.json but could not be loaded together with
+// the reason why they could not be loaded.
+func (p *processor) unloadedSignatureKeys(sig *crypto.PGPSignature) []string {
+	ids, ok := sig.GetSignatureKeyIDs()
+	if !ok {
+		return nil
+	}
+	var unloaded []string
+	for _, id := range ids {
+		if err, ok := p.unloadedKeys[id]; ok {
+			unloaded = append(unloaded, fmt.Sprintf("%016X (%v)", id, err))
+		}
+	}
+	return unloaded
+}
@@ -0,0 +1,133 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+func TestCheckPGPKeyQuality(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		config packet.Config
+		want   []string
+	}{
+		{
+			name:   "ed25519",
+			config: packet.Config{Algorithm: packet.PubKeyAlgoEdDSA},
+		},
+		{
+			name: "rsa 2048",
+			config: packet.Config{
+				Algorithm: packet.PubKeyAlgoRSA,
+				RSABits:   2048,
+			},
+			want: []string{"WARN:RSA with only 2048 bits"},
+		},
+		{
+			name: "soon to expire",
+			config: packet.Config{
+				Algorithm:       packet.PubKeyAlgoEdDSA,
+				KeyLifetimeSecs: 24 * 60 * 60,
+			},
+			want: []string{"WARN:expires on"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			entity, err := openpgp.NewEntity("test", "", "test@example.com", &test.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			key, err := crypto.NewKeyFromEntity(entity)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if key, err = key.ToPublic(); err != nil {
+				t.Fatal(err)
+			}
+
+			p, err := newProcessor(&config{KeyExpiryWindow: defaultKeyExpiryWindow})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer p.close()
+
+			p.checkPGPKeyQuality("https://example.com/key.asc", key)
+
+			if len(p.badPGPs) != len(test.want) {
+				t.Fatalf("got %d messages, want %d: %v", len(p.badPGPs), len(test.want), p.badPGPs)
+			}
+			for i, want := range test.want {
+				typ, text, _ := strings.Cut(want, ":")
+				if got := p.badPGPs[i]; got.Type.String() != typ || !strings.Contains(got.Text, text) {
+					t.Errorf("message %d: got %v, want %s", i, got, want)
+				}
+			}
+
+			// All keys of the entity must be known as listed.
+			if !p.keyIDs.Contains(entity.PrimaryKey.KeyId) {
+				t.Error("primary key id not registered")
+			}
+			for _, sub := range entity.Subkeys {
+				if !p.keyIDs.Contains(sub.PublicKey.KeyId) {
+					t.Error("subkey id not registered")
+				}
+			}
+		})
+	}
+}
+
+func TestUnlistedSignatureKeys(t *testing.T) {
+	signer, err := crypto.GenerateKey("test", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring, err := crypto.NewKeyRing(signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := ring.SignDetachedWithContext(
+		crypto.NewPlainMessage([]byte("{}")), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := newProcessor(&config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.close()
+
+	if unlisted := p.unlistedSignatureKeys(sig); len(unlisted) != 1 {
+		t.Fatalf("expected one unlisted key, got %v", unlisted)
+	}
+
+	p.recordUnloadedKey(signer.GetFingerprint(), errors.New("not found"))
+
+	if unlisted := p.unlistedSignatureKeys(sig); len(unlisted) != 0 {
+		t.Fatalf("expected no unlisted keys, got %v", unlisted)
+	}
+	unloaded := p.unloadedSignatureKeys(sig)
+	if len(unloaded) != 1 || !strings.HasSuffix(unloaded[0], "(not found)") {
+		t.Fatalf("expected one unloaded key, got %v", unloaded)
+	}
+	clear(p.unloadedKeys)
+
+	p.checkPGPKeyQuality("https://example.com/key.asc", signer)
+
+	if unlisted := p.unlistedSignatureKeys(sig); len(unlisted) != 0 {
+		t.Fatalf("expected no unlisted keys, got %v", unlisted)
+	}
+}
@@ -19,7 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"path/filepath"
@@ -51,17 +51,24 @@ type processor struct {
 
 	redirects      map[string][]string
 	noneTLS        util.Set[string]
+	tlsHosts       util.Set[string]
 	alreadyChecked map[string]whereType
 	pmdURL         string
 	pmd256         []byte
 	pmd            any
 	keys           *crypto.KeyRing
+	keyIDs         util.Set[uint64]
+	unloadedKeys   map[uint64]error
+	pgpKeyURLs     util.Set[string]
+	httpCaching    map[resourceKind]*httpCacheStats
 	labelChecker   labelChecker
 	timesChanges   map[string]time.Time
 	timesAdv       map[string]time.Time
+	timesROLIE     map[string]time.Time
 
 	invalidAdvisories      topicMessages
 	badFilenames           topicMessages
+	badTLS                 topicMessages
 	badIntegrities         topicMessages
 	badPGPs                topicMessages
 	badSignatures          topicMessages
@@ -78,6 +85,10 @@ type processor struct {
 	badROLIECategory       topicMessages
 	badWhitePermissions    topicMessages
 	badAmberRedPermissions topicMessages
+	badHTTPCaching         topicMessages
+	badAggregator          topicMessages
+	badIssuingParties      topicMessages
+	badMirrors             topicMessages
 
 	expr *util.PathEval
 }
@@ -196,7 +207,13 @@ func newProcessor(cfg *config) (*processor, error) {
 		},
 		timesAdv:     map[string]time.Time{},
 		timesChanges: map[string]time.Time{},
+		timesROLIE:   map[string]time.Time{},
 		noneTLS:      util.Set[string]{},
+		tlsHosts:     util.Set[string]{},
+		keyIDs:       util.Set[uint64]{},
+		unloadedKeys: map[uint64]error{},
+		pgpKeyURLs:   util.Set[string]{},
+		httpCaching:  map[resourceKind]*httpCacheStats{},
 	}, nil
 }
 
@@ -215,13 +232,20 @@ func (p *processor) reset() {
 	p.pmd256 = nil
 	p.pmd = nil
 	p.keys = nil
+	clear(p.keyIDs)
+	clear(p.unloadedKeys)
+	clear(p.pgpKeyURLs)
+	clear(p.httpCaching)
 	clear(p.alreadyChecked)
 	clear(p.noneTLS)
+	clear(p.tlsHosts)
 	clear(p.timesAdv)
 	clear(p.timesChanges)
+	clear(p.timesROLIE)
 
 	p.invalidAdvisories.reset()
 	p.badFilenames.reset()
+	p.badTLS.reset()
 	p.badIntegrities.reset()
 	p.badPGPs.reset()
 	p.badSignatures.reset()
@@ -238,6 +262,10 @@ func (p *processor) reset() {
 	p.badROLIECategory.reset()
 	p.badWhitePermissions.reset()
 	p.badAmberRedPermissions.reset()
+	p.badHTTPCaching.reset()
+	p.badAggregator.reset()
+	p.badIssuingParties.reset()
+	p.badMirrors.reset()
 	p.labelChecker.reset()
 }
 
@@ -254,8 +282,19 @@ func (p *processor) run(ctx context.Context, domains []string) (*Report, error)
 	for _, d := range domains {
 		p.reset()
 
+		if isAggregatorURL(d) {
+			report.Domains = append(report.Domains, p.runAggregator(ctx, d, d))
+			continue
+		}
+
 		domain := &Domain{Name: d}
 		if !p.checkProviderMetadata(ctx, d) {
+			// The domain may be the one of an aggregator.
+			if aggURL := p.discoverAggregator(ctx, d); aggURL != "" {
+				p.reset()
+				report.Domains = append(report.Domains, p.runAggregator(ctx, d, aggURL))
+				continue
+			}
 			// We need to fail the domain if the PMD cannot be parsed.
 			p.badProviderMetadata.use()
 			p.badProviderMetadata.error("Could not parse the Provider-Metadata.json of: %s", d)
@@ -272,13 +311,13 @@ func (p *processor) run(ctx context.Context, domains []string) (*Report, error)
 		}
 
 		if err := p.fillMeta(domain); err != nil {
-			log.Printf("Filling meta data failed: %v\n", err)
+			slog.Error("Filling meta data failed", "domain", d, "error", err)
 			// reporters depend on role.
 			continue
 		}
 
 		if domain.Role == nil {
-			log.Printf("No role found in meta data for domain %q\n", d)
+			slog.Warn("No role found in meta data", "domain", d)
 			// Assume trusted provider to continue report generation
 			role := csaf.MetadataRoleTrustedProvider
 			domain.Role = &role
@@ -287,9 +326,9 @@ func (p *processor) run(ctx context.Context, domains []string) (*Report, error)
 		rules := roleRequirements(*domain.Role)
 		// TODO: store error base on rules eval in report.
 		if rules == nil {
-			log.Printf(
-				"WARN: Cannot find requirement rules for role %q. Assuming trusted provider.\n",
-				*domain.Role)
+			slog.Warn("Cannot find requirement rules for role. Assuming trusted provider.",
+				"domain", d,
+				"role", *domain.Role)
 			rules = trustedProviderRules
 		}
 
@@ -298,6 +337,10 @@ func (p *processor) run(ctx context.Context, domains []string) (*Report, error)
 			r.report(p, domain)
 		}
 
+		for _, r := range informationalReporters {
+			r.report(p, domain)
+		}
+
 		if evaluated := rules.eval(p); evaluated != nil {
 			domain.EvaluatedRules = evaluated
 			domain.Passed = evaluated.passed()
@@ -365,9 +408,11 @@ func (p *processor) domainChecks(domain string) []func(*processor, context.Conte
 	checks = append(checks,
 		(*processor).checkCSAFs,
 		(*processor).checkMissing,
+		(*processor).checkReleaseDates,
 		(*processor).checkInvalid,
 		(*processor).checkListing,
 		(*processor).checkWhitePermissions,
+		(*processor).checkHTTPCaching,
 	)
 
 	return checks
@@ -428,7 +473,8 @@ func (p *processor) fullClient() util.ClientWithContext {
 
 	hClient.CheckRedirect = p.checkRedirect
 
-	var tlsConfig tls.Config
+	// Allow older TLS versions to be able to report them.
+	tlsConfig := tls.Config{MinVersion: tls.VersionTLS10}
 	if p.cfg.Insecure {
 		tlsConfig.InsecureSkipVerify = true
 	}
@@ -437,9 +483,12 @@ func (p *processor) fullClient() util.ClientWithContext {
 		tlsConfig.Certificates = p.cfg.clientCerts
 	}
 
-	hClient.Transport = &http.Transport{
-		TLSClientConfig: &tlsConfig,
-		Proxy:           http.ProxyFromEnvironment,
+	hClient.Transport = &inspectingTransport{
+		RoundTripper: &http.Transport{
+			TLSClientConfig: &tlsConfig,
+			Proxy:           p.cfg.proxies.Proxy,
+		},
+		p: p,
 	}
 
 	client := util.Client(&hClient)
@@ -463,23 +512,30 @@ func (p *processor) fullClient() util.ClientWithContext {
 			Limiter: rate.NewLimiter(rate.Limit(*p.cfg.Rate), 1),
 		}
 	}
-	p.client = cwc
+
+	// Retry transient failures and cache the responses.
+	p.client = &recordingClient{
+		ClientWithContext: p.cfg.cachingClient(p.cfg.retryingClient(cwc)),
+		p:                 p,
+	}
 	return p.client
 }
 
 // basicClient returns a http Client w/o certs and headers.
 func (p *processor) basicClient() util.ClientWithContext {
 	hClient := http.Client{}
-	if p.cfg.Insecure {
+	if p.cfg.Insecure || p.cfg.proxies != nil {
 		hClient.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: p.cfg.Insecure},
+			Proxy:           p.cfg.proxies.Proxy,
 		}
 	}
 	client := util.Client(&hClient)
-	return &util.BasicClient{
+	// Not cached to not answer with responses
+	// obtained with certificates or extra headers.
+	return p.cfg.retryingClient(&util.BasicClient{
 		Client: client,
-	}
+	})
 }
 
 // httpClient returns a cached HTTP client to be used to
@@ -583,6 +639,9 @@ func (p *processor) extractAdvisoryFilesFromROLIE(
 				`ROLIE feed %s contains entry link with no "self" URL.`, feed)
 			return
 		}
+		if !sr.Updated.IsZero() {
+			p.timesROLIE[url] = sr.Updated
+		}
 		switch {
 		case sha256 == "" && sha512 != "":
 			p.badROLIEFeed.info("%s has no sha256 hash file listed", url)
@@ -722,6 +781,9 @@ func (p *processor) extractWithModel(feed string, res *http.Response) ([]csaf.Ad
 				`ROLIE feed %s contains entry link with no "self" URL.`, feed)
 			return
 		}
+		if t := time.Time(entry.Updated); !t.IsZero() {
+			p.timesROLIE[url] = t
+		}
 
 		var file csaf.AdvisoryFile
 
@@ -805,9 +867,7 @@ func (p *processor) integrity(
 
 		// Should this URL be ignored?
 		if p.cfg.ignoreURL(u) {
-			if p.cfg.Verbose {
-				log.Printf("Ignoring %q\n", u)
-			}
+			slog.Debug("Ignoring advisory", "url", u)
 			return nil
 		}
 
@@ -1019,6 +1079,18 @@ func (p *processor) integrity(
 				return
 			}
 			if p.keys != nil {
+				if unloaded := p.unloadedSignatureKeys(sig); len(unloaded) > 0 {
+					p.badSignatures.error(
+						"Signature %s was made with key(s) %s listed in provider-metadata.json which could not be loaded.",
+						sigFile, strings.Join(unloaded, ", "))
+					return
+				}
+				if unlisted := p.unlistedSignatureKeys(sig); len(unlisted) > 0 {
+					p.badSignatures.error(
+						"Signature %s was made with key(s) %s not listed in provider-metadata.json.",
+						sigFile, strings.Join(unlisted, ", "))
+					return
+				}
 				pm := crypto.NewPlainMessage(data.Bytes())
 				t := crypto.GetUnixTime()
 				if err := p.keys.VerifyDetached(pm, sig, t); err != nil {
@@ -1037,26 +1109,6 @@ func (p *processor) integrity(
 		}
 	}
 
-	// If we tested an existing changes.csv
-	if len(p.timesAdv) > 0 && p.badChanges.used() {
-		// Iterate over all files again
-		for _, f := range files {
-			// If there was no previous error when extracting times from advisories and we have a valid time
-			if timeAdv, ok := p.timesAdv[f.URL()]; ok {
-				// If there was no previous error when extracting times from changes and the file was listed in changes.csv
-				if timeCha, ok := p.timesChanges[f.URL()]; ok {
-					// check if the time matches
-					if !timeAdv.Equal(timeCha) {
-						// if not, give an error and remove the pair so it isn't reported multiple times should integrity be called again
-						p.badChanges.error("Current release date in changes.csv and %s is not identical.", f.URL())
-						delete(p.timesAdv, f.URL())
-						delete(p.timesChanges, f.URL())
-					}
-				}
-			}
-		}
-	}
-
 	return nil
 }
 
@@ -1214,9 +1266,13 @@ func (p *processor) checkChanges(ctx context.Context, base string, mask whereTyp
 				return nil, nil, err
 			}
 
+			abs := misc.JoinURL(bu, pathURL).String()
+			if _, dup := p.timesChanges[abs]; dup {
+				p.badChanges.warn("%s is listed more than once in %s.", path, changes)
+			}
 			times, files = append(times, t),
-				append(files, csaf.DirectoryAdvisoryFile{Path: misc.JoinURL(bu, pathURL).String()})
-			p.timesChanges[path] = t
+				append(files, csaf.DirectoryAdvisoryFile{Path: abs})
+			p.timesChanges[abs] = t
 		}
 		return times, files, nil
 	}()
@@ -1385,6 +1441,32 @@ func (p *processor) checkMissing(context.Context, string) error {
 	return nil
 }
 
+// checkReleaseDates compares the current release dates of the
+// advisories with the dates listed in changes.csv and with
+// the "updated" fields of the ROLIE feed entries.
+func (p *processor) checkReleaseDates(context.Context, string) error {
+	files := make([]string, 0, len(p.timesAdv))
+	for f := range p.timesAdv {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+
+	for _, f := range files {
+		current := p.timesAdv[f]
+		if changed, ok := p.timesChanges[f]; ok && !current.Equal(changed) {
+			p.badChanges.error(
+				"Current release date in changes.csv (%s) and %s (%s) is not identical.",
+				changed.Format(time.RFC3339), f, current.Format(time.RFC3339))
+		}
+		if updated, ok := p.timesROLIE[f]; ok && !current.Equal(updated) {
+			p.badROLIEFeed.error(
+				"Updated date of ROLIE feed entry (%s) and current release date of %s (%s) are not identical.",
+				updated.Format(time.RFC3339), f, current.Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
 // checkInvalid goes over all found adivisories URLs and checks
 // if file name conforms to standard.
 func (p *processor) checkInvalid(context.Context, string) error {
@@ -1671,6 +1753,7 @@ func (p *processor) checkWellknownSecurityDNS(ctx context.Context, domain string
 
 // checkPGPKeys checks if the OpenPGP keys are available and valid, fetches
 // the remote pubkeys and compares the fingerprints.
+// The quality of the loaded keys is checked, too.
 // As a result of these checks respective error messages are passed
 // to badPGP methods. It returns nil if all checks are passed.
 func (p *processor) checkPGPKeys(ctx context.Context, _ string) error {
@@ -1701,21 +1784,25 @@ func (p *processor) checkPGPKeys(ctx context.Context, _ string) error {
 		key := &keys[i]
 		if key.URL == nil {
 			p.badPGPs.error("Missing URL for fingerprint %x.", key.Fingerprint)
+			p.recordUnloadedKey(string(key.Fingerprint), errors.New("missing URL"))
 			continue
 		}
 		up, err := url.Parse(*key.URL)
 		if err != nil {
 			p.badPGPs.error("Invalid URL '%s': %v", *key.URL, err)
+			p.recordUnloadedKey(string(key.Fingerprint), err)
 			continue
 		}
 
 		// Todo: refactor all methods to directly accept *url.URL
 		u := up.String()
 		p.checkTLS(u)
+		p.pgpKeyURLs.Add(u)
 
 		res, err := client.GetWithContext(ctx, *key.URL)
 		if err != nil {
 			p.badPGPs.error("Fetching public OpenPGP key %s failed: %v.", u, err)
+			p.recordUnloadedKey(string(key.Fingerprint), err)
 			continue
 		}
 		check := func() {
@@ -1723,11 +1810,16 @@ func (p *processor) checkPGPKeys(ctx context.Context, _ string) error {
 			if res.StatusCode != http.StatusOK {
 				p.badPGPs.error("Fetching public OpenPGP key %s status code: %d (%s)",
 					u, res.StatusCode, res.Status)
+				p.recordUnloadedKey(string(key.Fingerprint),
+					fmt.Errorf("fetching %s failed: status code %d (%s)",
+						u, res.StatusCode, res.Status))
 				return
 			}
 			ckey, err := crypto.NewKeyFromArmoredReader(res.Body)
 			if err != nil {
 				p.badPGPs.error("Reading public OpenPGP key %s failed: %v", u, err)
+				p.recordUnloadedKey(string(key.Fingerprint),
+					fmt.Errorf("reading %s failed: %w", u, err))
 				return
 			}
 			if !strings.EqualFold(
@@ -1738,8 +1830,11 @@ func (p *processor) checkPGPKeys(ctx context.Context, _ string) error {
 					"Given Fingerprint (%q) of public OpenPGP key %q "+
 						"does not match remotely loaded (%q).",
 					string(key.Fingerprint), u, ckey.GetFingerprint())
+				p.recordUnloadedKey(string(key.Fingerprint),
+					fmt.Errorf("fingerprint of %s does not match", u))
 				return
 			}
+			p.checkPGPKeyQuality(u, ckey)
 			if p.keys == nil {
 				if keyring, err := crypto.NewKeyRing(ckey); err != nil {
 					p.badPGPs.error(
@@ -12,6 +12,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"net/http"
 	"net/http/httptest"
 	"os"
 	"reflect"
@@ -19,6 +20,7 @@ import (
 	"strings"
 	"testing"
 	"text/template"
+	"time"
 
 	"github.com/gocsaf/csaf/v3/internal/testutil"
 	"github.com/gocsaf/csaf/v3/util"
@@ -258,3 +260,105 @@ func TestShaMarking(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckReleaseDates(t *testing.T) {
+	p, err := newProcessor(&config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.close()
+
+	released := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := released.Add(time.Hour)
+
+	const (
+		good  = "https://example.com/white/2020/good.json"
+		stale = "https://example.com/white/2020/stale.json"
+	)
+	p.timesAdv[good] = released
+	p.timesAdv[stale] = later
+	// Same instant in another zone is fine.
+	p.timesChanges[good] = released.In(time.FixedZone("CET", 3600))
+	p.timesROLIE[good] = released
+	p.timesChanges[stale] = released
+	p.timesROLIE[stale] = released
+
+	if err := p.checkReleaseDates(context.Background(), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, msgs := range []topicMessages{p.badChanges, p.badROLIEFeed} {
+		if len(msgs) != 1 {
+			t.Fatalf("expected one message, got %v", msgs)
+		}
+		if msg := msgs[0]; msg.Type != ErrorType || !strings.Contains(msg.Text, stale) {
+			t.Errorf("unexpected message %v", msg)
+		}
+	}
+}
+
+func TestCheckChanges(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		changes  string
+		dup      bool
+		unsorted bool
+	}{
+		{
+			name: "consistent",
+			changes: `"2020/a-0002.json","2020-01-02T00:00:00Z"
+"2020/a-0001.json","2020-01-01T00:00:00Z"
+`,
+		},
+		{
+			name: "duplicate",
+			changes: `"2020/a-0002.json","2020-01-02T00:00:00Z"
+"2020/a-0001.json","2020-01-01T00:00:00Z"
+"2020/a-0001.json","2020-01-01T00:00:00Z"
+`,
+			dup: true,
+		},
+		{
+			name: "unsorted",
+			changes: `"2020/a-0001.json","2020-01-01T00:00:00Z"
+"2020/a-0002.json","2020-01-02T00:00:00Z"
+`,
+			unsorted: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewTLSServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					if r.URL.Path == "/white/changes.csv" {
+						w.Write([]byte(test.changes))
+						return
+					}
+					http.NotFound(w, r)
+				}))
+			defer server.Close()
+
+			p, err := newProcessor(&config{Insecure: true, Retries: new(int)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer p.close()
+
+			if err := p.checkChanges(
+				context.Background(), server.URL+"/white/", 0); err != nil {
+				t.Fatal(err)
+			}
+
+			has := func(typ MessageType, text string) bool {
+				return slices.ContainsFunc(p.badChanges, func(m Message) bool {
+					return m.Type == typ && strings.Contains(m.Text, text)
+				})
+			}
+			if got := has(WarnType, "2020/a-0001.json is listed more than once"); got != test.dup {
+				t.Errorf("duplicate warning: got %t, want %t: %v", got, test.dup, p.badChanges)
+			}
+			if got := has(ErrorType, "is not sorted in descending order"); got != test.unsorted {
+				t.Errorf("sort error: got %t, want %t: %v", got, test.unsorted, p.badChanges)
+			}
+		})
+	}
+}
@@ -49,12 +49,14 @@ type Requirement struct {
 
 // Domain are the results of a domain.
 type Domain struct {
-	Name           string             `json:"name"`
-	Publisher      *csaf.Publisher    `json:"publisher,omitempty"`
-	Role           *csaf.MetadataRole `json:"role,omitempty"`
-	Requirements   []*Requirement     `json:"requirements,omitempty"`
-	Passed         bool               `json:"passed"`
-	EvaluatedRules *requirementRules  `json:"evaluated_rules,omitempty"`
+	Name           string               `json:"name"`
+	Publisher      *csaf.Publisher      `json:"publisher,omitempty"`
+	Role           *csaf.MetadataRole   `json:"role,omitempty"`
+	Aggregator     *csaf.AggregatorInfo `json:"aggregator,omitempty"`
+	Requirements   []*Requirement       `json:"requirements,omitempty"`
+	Informational  []*Requirement       `json:"informational,omitempty"`
+	Passed         bool                 `json:"passed"`
+	EvaluatedRules *requirementRules    `json:"evaluated_rules,omitempty"`
 }
 
 // ReportTime stores the time of the report.
@@ -44,6 +44,7 @@ type (
 	listReporter              struct{ baseReporter }
 	hasTwoReporter            struct{ baseReporter }
 	mirrorReporter            struct{ baseReporter }
+	httpCachingReporter       struct{ baseReporter }
 )
 
 var reporters = [...]reporter{
@@ -72,6 +73,13 @@ var reporters = [...]reporter{
 	23: &mirrorReporter{baseReporter{num: 23, description: "Mirror"}},
 }
 
+// informationalReporters are the reporters of findings which
+// are not covered by the requirements of the CSAF standard.
+// They do not influence the overall result.
+var informationalReporters = [...]reporter{
+	&httpCachingReporter{baseReporter{description: "HTTP caching and content types"}},
+}
+
 func (bc *baseReporter) requirement(domain *Domain) *Requirement {
 	req := &Requirement{
 		Num:         bc.num,
@@ -81,6 +89,16 @@ func (bc *baseReporter) requirement(domain *Domain) *Requirement {
 	return req
 }
 
+// informational adds an informational section to the given domain.
+func (bc *baseReporter) informational(domain *Domain) *Requirement {
+	req := &Requirement{
+		Num:         bc.num,
+		Description: bc.description,
+	}
+	domain.Informational = append(domain.Informational, req)
+	return req
+}
+
 // contains returns whether any of vs is present in s.
 func containsAny[E comparable](s []E, vs ...E) bool {
 	for _, e := range s {
@@ -129,8 +147,11 @@ func (r *filenameReporter) report(p *processor, domain *Domain) {
 // report tests if the URLs are HTTPS and sets the "message" field value
 // of the "Requirement" struct as a result of that.
 // A list of non HTTPS URLs is included in the value of the "message" field.
+// The findings about the served certificates and the TLS connections
+// are appended.
 func (r *tlsReporter) report(p *processor, domain *Domain) {
 	req := r.requirement(domain)
+	defer req.Append(p.badTLS)
 	if p.noneTLS == nil {
 		req.message(InfoType, "No TLS checks performed.")
 		return
@@ -421,14 +442,28 @@ func (r *publicPGPKeyReporter) report(p *processor, domain *Domain) {
 // report tests whether a CSAF aggregator JSON schema conform
 // aggregator.json exists without being adjacent to a
 // provider-metadata.json
-func (r *listReporter) report(_ *processor, _ *Domain) {
-	// TODO
+func (r *listReporter) report(p *processor, domain *Domain) {
+	req := r.requirement(domain)
+	if !p.badAggregator.used() {
+		req.message(InfoType, "No aggregator.json checked.")
+		return
+	}
+	if len(p.badAggregator) == 0 {
+		req.message(InfoType, "aggregator.json and all listed provider-metadata.json files are valid.")
+		return
+	}
+	req.Messages = p.badAggregator
 }
 
 // report tests whether the aggregator.json lists at least
-// two disjoint issuing parties. TODO: reevaluate phrasing (Req 7.1.22)
-func (r *hasTwoReporter) report(_ *processor, _ *Domain) {
-	// TODO
+// two disjoint issuing parties.
+func (r *hasTwoReporter) report(p *processor, domain *Domain) {
+	req := r.requirement(domain)
+	if !p.badIssuingParties.used() {
+		req.message(InfoType, "No issuing parties checked.")
+		return
+	}
+	req.Messages = p.badIssuingParties
 }
 
 // report tests whether the CSAF documents of each issuing mirrored party
@@ -436,6 +471,30 @@ func (r *hasTwoReporter) report(_ *processor, _ *Domain) {
 // if the folder name is retrieved from the name of the issuing authority.
 // It also tests whether each folder has a provider-metadata.json for their
 // party and provides ROLIE feed documents.
-func (r *mirrorReporter) report(_ *processor, _ *Domain) {
-	// TODO
+func (r *mirrorReporter) report(p *processor, domain *Domain) {
+	req := r.requirement(domain)
+	if !p.badMirrors.used() {
+		req.message(InfoType, "No mirrors checked.")
+		return
+	}
+	if len(p.badMirrors) == 0 {
+		req.message(InfoType, "All checked mirrors are consistent with their originals.")
+		return
+	}
+	req.Messages = p.badMirrors
+}
+
+// report reports if the resources were served with the expected
+// content types and if they support conditional requests.
+func (r *httpCachingReporter) report(p *processor, domain *Domain) {
+	req := r.informational(domain)
+	if !p.badHTTPCaching.used() {
+		req.message(InfoType, "No HTTP caching checks performed.")
+		return
+	}
+	if len(p.badHTTPCaching) == 0 {
+		req.message(InfoType, "All resources were served with the expected content types and support conditional requests.")
+		return
+	}
+	req.Messages = p.badHTTPCaching
 }
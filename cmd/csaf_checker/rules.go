@@ -67,6 +67,16 @@ var (
 			{Condition: condAll, Includes: ruleAtoms(18, 19, 20)},
 		},
 	}
+
+	listerRules = &requirementRules{
+		Condition: condAll,
+		Includes:  ruleAtoms(6, 21, 22),
+	}
+
+	aggregatorRules = &requirementRules{
+		Condition: condAll,
+		Includes:  ruleAtoms(1, 2, 3, 6, 21, 22, 23),
+	}
 )
 
 func (rules *requirementRules) clone() *requirementRules {
@@ -205,7 +215,7 @@ func (p *processor) eval(requirement int) bool {
 	case 2:
 		return !p.badFilenames.hasErrors()
 	case 3:
-		return len(p.noneTLS) == 0
+		return len(p.noneTLS) == 0 && !p.badTLS.hasErrors()
 	case 4:
 		return !p.badWhitePermissions.hasErrors()
 	case 5:
@@ -246,6 +256,13 @@ func (p *processor) eval(requirement int) bool {
 		return !p.badSignatures.hasErrors()
 	case 20:
 		return !p.badPGPs.hasErrors()
+
+	case 21:
+		return !p.badAggregator.hasErrors()
+	case 22:
+		return !p.badIssuingParties.hasErrors()
+	case 23:
+		return !p.badMirrors.hasErrors()
 	default:
 		panic(fmt.Sprintf("evaluating unexpected requirement %d", requirement))
 	}
@@ -0,0 +1,174 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"bytes"
+	"crypto/dsa" //lint:ignore SA1019 Only used to detect weak keys.
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// minRSAKeySize is the minimal accepted size of RSA keys in bits.
+	minRSAKeySize = 2048
+	// minECDSAKeySize is the minimal accepted size of ECDSA keys in bits.
+	minECDSAKeySize = 256
+)
+
+// weakSignatureAlgorithm returns true if the given algorithm
+// is not considered to be safe any more.
+func weakSignatureAlgorithm(alg x509.SignatureAlgorithm) bool {
+	switch alg {
+	case x509.MD2WithRSA,
+		x509.MD5WithRSA,
+		x509.SHA1WithRSA,
+		x509.DSAWithSHA1,
+		x509.DSAWithSHA256,
+		x509.ECDSAWithSHA1:
+		return true
+	}
+	return false
+}
+
+// weakKey returns a description of the public key of the given
+// certificate if it is considered too weak. Otherwise an empty string.
+func weakKey(cert *x509.Certificate) string {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		if bits := key.N.BitLen(); bits < minRSAKeySize {
+			return "RSA " + strconv.Itoa(bits) + " bit"
+		}
+	case *ecdsa.PublicKey:
+		if bits := key.Curve.Params().BitSize; bits < minECDSAKeySize {
+			return "ECDSA " + strconv.Itoa(bits) + " bit"
+		}
+	case *dsa.PublicKey:
+		return "DSA"
+	}
+	return ""
+}
+
+// selfSigned returns true if the given certificate is self-issued.
+func selfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawSubject, cert.RawIssuer)
+}
+
+// hasHSTS checks if the response carries a
+// Strict-Transport-Security header with a positive max-age.
+func hasHSTS(res *http.Response) bool {
+	for _, directive := range strings.Split(res.Header.Get("Strict-Transport-Security"), ";") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		if !strings.EqualFold(name, "max-age") {
+			continue
+		}
+		age, err := strconv.Atoi(strings.Trim(value, `"`))
+		return err == nil && age > 0
+	}
+	return false
+}
+
+// checkTLSFailure reports the cause of a failed certificate
+// verification during the TLS handshake with the given host.
+// Other errors are left to the callers.
+func (p *processor) checkTLSFailure(host string, err error) {
+	if p.tlsHosts.Contains(host) {
+		return
+	}
+	var (
+		hostErr      x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+		authorityErr x509.UnknownAuthorityError
+	)
+	switch {
+	case errors.As(err, &hostErr):
+		p.badTLS.error("Certificate of %s does not match host name: %v", host, hostErr)
+	case errors.As(err, &invalidErr) && invalidErr.Reason == x509.Expired:
+		p.badTLS.error("Certificate %q of %s is expired or not yet valid: %v",
+			invalidErr.Cert.Subject.String(), host, invalidErr)
+	case errors.As(err, &invalidErr):
+		p.badTLS.error("Certificate %q of %s is invalid: %v",
+			invalidErr.Cert.Subject.String(), host, invalidErr)
+	case errors.As(err, &authorityErr):
+		p.badTLS.error("Certificate of %s is signed by an unknown authority: %v",
+			host, authorityErr)
+	default:
+		return
+	}
+	p.badTLS.use()
+	// The connection could not be established so there is nothing
+	// more to inspect for this host.
+	p.tlsHosts.Add(host)
+}
+
+// checkTLSConnection inspects the TLS connection state of the given
+// response. Every host is only inspected once.
+func (p *processor) checkTLSConnection(host string, res *http.Response) {
+	if p.tlsHosts.Contains(host) {
+		return
+	}
+	p.tlsHosts.Add(host)
+
+	p.badTLS.use()
+	state := res.TLS
+
+	if state.Version < tls.VersionTLS12 {
+		p.badTLS.error("%s uses %s which is below TLS 1.2.",
+			host, tls.VersionName(state.Version))
+	}
+
+	if !hasHSTS(res) {
+		p.badTLS.warn("%s does not send a Strict-Transport-Security (HSTS) header.", host)
+	}
+
+	if len(state.PeerCertificates) == 0 {
+		p.badTLS.error("%s served no certificates.", host)
+		return
+	}
+
+	leaf := state.PeerCertificates[0]
+	if err := leaf.VerifyHostname(host); err != nil {
+		p.badTLS.error("Certificate of %s does not match host name: %v", host, err)
+	}
+
+	now := time.Now()
+	window := now.Add(p.cfg.CertExpiryWindow)
+
+	for _, cert := range state.PeerCertificates {
+		subject := cert.Subject.String()
+		switch {
+		case now.After(cert.NotAfter):
+			p.badTLS.error("Certificate %q of %s expired on %s.",
+				subject, host, cert.NotAfter.UTC().Format(time.RFC3339))
+		case window.After(cert.NotAfter):
+			p.badTLS.warn("Certificate %q of %s expires on %s.",
+				subject, host, cert.NotAfter.UTC().Format(time.RFC3339))
+		}
+		if now.Before(cert.NotBefore) {
+			p.badTLS.error("Certificate %q of %s is not valid before %s.",
+				subject, host, cert.NotBefore.UTC().Format(time.RFC3339))
+		}
+		if weak := weakKey(cert); weak != "" {
+			p.badTLS.error("Certificate %q of %s uses a weak key (%s).",
+				subject, host, weak)
+		}
+		// The signature of a self-signed root is not used to establish trust.
+		if !selfSigned(cert) && weakSignatureAlgorithm(cert.SignatureAlgorithm) {
+			p.badTLS.error("Certificate %q of %s is signed with weak algorithm %s.",
+				subject, host, cert.SignatureAlgorithm)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHasHSTS(t *testing.T) {
+	for _, test := range []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{"max-age=31536000", true},
+		{"max-age=31536000; includeSubDomains", true},
+		{`includeSubDomains; max-age="600"`, true},
+		{"max-age=0", false},
+		{"includeSubDomains", false},
+	} {
+		res := &http.Response{Header: http.Header{}}
+		if test.header != "" {
+			res.Header.Set("Strict-Transport-Security", test.header)
+		}
+		if got := hasHSTS(res); got != test.want {
+			t.Errorf("hasHSTS(%q): got %t, want %t", test.header, got, test.want)
+		}
+	}
+}
+
+func TestCheckTLSConnection(t *testing.T) {
+	var hsts bool
+	server := httptest.NewTLSServer(http.HandlerFunc(
+		func(w http.ResponseWriter, _ *http.Request) {
+			if hsts {
+				w.Header().Set("Strict-Transport-Security", "max-age=600")
+			}
+		}))
+	defer server.Close()
+
+	for _, test := range []struct {
+		name   string
+		hsts   bool
+		window time.Duration
+		want   []MessageType
+	}{
+		{"hsts", true, defaultCertExpiryWindow, nil},
+		{"no hsts", false, defaultCertExpiryWindow, []MessageType{WarnType}},
+		{"expiry", true, 200 * 365 * 24 * time.Hour, []MessageType{WarnType}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			hsts = test.hsts
+			cfg := config{Insecure: true, CertExpiryWindow: test.window}
+			p, err := newProcessor(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer p.close()
+
+			res, err := p.httpClient().GetWithContext(context.Background(), server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+
+			if !p.badTLS.used() {
+				t.Fatal("TLS connection was not inspected")
+			}
+			if len(p.badTLS) != len(test.want) {
+				t.Fatalf("got %d messages, want %d: %v", len(p.badTLS), len(test.want), p.badTLS)
+			}
+			for i, typ := range test.want {
+				if p.badTLS[i].Type != typ {
+					t.Errorf("message %d: got type %v, want %v", i, p.badTLS[i].Type, typ)
+				}
+			}
+			if test.window > defaultCertExpiryWindow &&
+				!strings.Contains(p.badTLS[0].Text, "expires on") {
+				t.Errorf("unexpected message: %q", p.badTLS[0].Text)
+			}
+			if !p.eval(3) {
+				t.Error("requirement 3 should pass with warnings only")
+			}
+		})
+	}
+}
+
+func TestCheckTLSFailure(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(
+		func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	noRetries := 0
+	cfg := config{CertExpiryWindow: defaultCertExpiryWindow, Retries: &noRetries}
+	p, err := newProcessor(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.close()
+
+	if _, err := p.httpClient().GetWithContext(context.Background(), server.URL); err == nil {
+		t.Fatal("expected certificate verification to fail")
+	}
+
+	if len(p.badTLS) != 1 {
+		t.Fatalf("got %d messages, want 1: %v", len(p.badTLS), p.badTLS)
+	}
+	if msg := p.badTLS[0]; msg.Type != ErrorType ||
+		!strings.Contains(msg.Text, "unknown authority") {
+		t.Errorf("unexpected message: %v", msg)
+	}
+	if p.eval(3) {
+		t.Error("requirement 3 should fail")
+	}
+}
@@ -0,0 +1,167 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/gocsaf/csaf/v3/csaf"
+	"github.com/gocsaf/csaf/v3/internal/misc"
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+// loadAggregator loads the aggregator.json from the given URL.
+func loadAggregator(
+	ctx context.Context,
+	client util.ClientWithContext,
+	u string,
+) (*csaf.Aggregator, error) {
+	res, err := client.GetWithContext(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status code %d (%s)", res.StatusCode, res.Status)
+	}
+	var agg csaf.Aggregator
+	if err := misc.StrictJSONParse(res.Body, &agg); err != nil {
+		return nil, err
+	}
+	return &agg, nil
+}
+
+// aggregatorEntry is a provider or publisher listed in an aggregator.
+type aggregatorEntry struct {
+	metadata *csaf.AggregatorCSAFProviderMetadata
+	mirrors  []csaf.ProviderURL
+}
+
+// aggregatorEntries returns the providers and publishers listed in the aggregator.
+func aggregatorEntries(agg *csaf.Aggregator) []aggregatorEntry {
+	var entries []aggregatorEntry
+	for _, p := range agg.CSAFProviders {
+		if p != nil {
+			entries = append(entries, aggregatorEntry{p.Metadata, p.Mirrors})
+		}
+	}
+	for _, p := range agg.CSAFPublishers {
+		if p != nil {
+			entries = append(entries, aggregatorEntry{p.Metadata, p.Mirrors})
+		}
+	}
+	return entries
+}
+
+// acceptAggregatorEntry checks the entry against the configured
+// include and exclude patterns. They are matched against
+// the name and the namespace of the publisher and the URL
+// of the provider-metadata.json.
+func (cfg *config) acceptAggregatorEntry(e aggregatorEntry) bool {
+	keys := []string{string(*e.metadata.URL)}
+	if pub := e.metadata.Publisher; pub != nil {
+		if pub.Name != nil {
+			keys = append(keys, *pub.Name)
+		}
+		if pub.Namespace != nil {
+			keys = append(keys, *pub.Namespace)
+		}
+	}
+	if len(cfg.AggregatorInclude) > 0 &&
+		!slices.ContainsFunc(keys, cfg.aggregatorInclude.Matches) {
+		return false
+	}
+	return !slices.ContainsFunc(keys, cfg.aggregatorExclude.Matches)
+}
+
+// addAggregatorDomains adds the providers and publishers listed
+// in the configured aggregators to the given domains.
+// They are identified by the URL of their provider-metadata.json.
+// The URLs of their mirrors are remembered as alternatives.
+func (d *downloader) addAggregatorDomains(ctx context.Context, domains []string) []string {
+	d.aggregatorFailed = false
+	if len(d.cfg.Aggregators) == 0 {
+		return domains
+	}
+	client := d.httpClient()
+	for _, u := range d.cfg.Aggregators {
+		agg, err := loadAggregator(ctx, client, u)
+		if err != nil {
+			slog.Error("Loading aggregator failed",
+				"url", u,
+				"error", err)
+			d.aggregatorFailed = true
+			continue
+		}
+		var added int
+		for _, e := range aggregatorEntries(agg) {
+			if e.metadata == nil || e.metadata.URL == nil {
+				continue
+			}
+			pmdURL := string(*e.metadata.URL)
+			if slices.Contains(domains, pmdURL) {
+				continue
+			}
+			if !d.cfg.acceptAggregatorEntry(e) {
+				slog.Debug("Ignoring provider listed in aggregator",
+					"url", pmdURL)
+				continue
+			}
+			domains = append(domains, pmdURL)
+			added++
+			if len(e.mirrors) == 0 {
+				continue
+			}
+			mirrors := make([]string, 0, len(e.mirrors))
+			for _, m := range e.mirrors {
+				mirrors = append(mirrors, string(m))
+			}
+			if d.cfg.PreferMirrors {
+				d.alternatives[pmdURL] = append(mirrors, pmdURL)
+			} else {
+				d.alternatives[pmdURL] = append([]string{pmdURL}, mirrors...)
+			}
+		}
+		slog.Info("Added providers listed in aggregator",
+			"url", u,
+			"count", added)
+	}
+	return domains
+}
+
+// loadProviderMetadata loads the provider-metadata.json of a domain.
+// If there are alternatives (e.g. mirrors) they are tried in order
+// till a valid one is found.
+func (d *downloader) loadProviderMetadata(
+	ctx context.Context,
+	loader *csaf.ProviderMetadataLoader,
+	domain string,
+) *csaf.LoadedProviderMetadata {
+	alternatives := d.alternatives[domain]
+	if len(alternatives) == 0 {
+		return loader.LoadWithContext(ctx, domain)
+	}
+	var lpmd *csaf.LoadedProviderMetadata
+	for i, u := range alternatives {
+		if lpmd = loader.LoadWithContext(ctx, u); lpmd.Valid() {
+			break
+		}
+		if i < len(alternatives)-1 {
+			slog.Warn("Loading provider-metadata.json failed, trying next source",
+				"domain", domain,
+				"url", u,
+				"next", alternatives[i+1])
+		}
+	}
+	return lpmd
+}
@@ -0,0 +1,124 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gocsaf/csaf/v3/internal/options"
+	"github.com/gocsaf/csaf/v3/internal/testutil"
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+// aggregatorTemplate is an aggregator.json listing the test provider
+// with a mirror which is not reachable.
+const aggregatorTemplate = `{
+  "aggregator": {
+    "category": "aggregator",
+    "contact_details": "mailto:aggregator@example.com",
+    "name": "Test aggregator",
+    "namespace": "https://aggregator.example.com"
+  },
+  "aggregator_version": "2.0",
+  "canonical_url": "%[1]s/aggregator.json",
+  "csaf_providers": [
+    {
+      "metadata": {
+        "last_updated": "2020-01-01T00:00:00Z",
+        "publisher": {
+          "category": "vendor",
+          "name": "ACME Inc",
+          "namespace": "https://example.com",
+          "contact_details": "mailto:security@example.com"
+        },
+        "role": "csaf_trusted_provider",
+        "url": "%[1]s/provider-metadata.json"
+      },
+      "mirrors": [ "%[1]s/mirror/provider-metadata.json" ]
+    }
+  ],
+  "last_updated": "2020-01-01T00:00:00Z"
+}`
+
+func TestAggregatorSource(t *testing.T) {
+	params := testutil.ProviderParams{
+		EnableSha256: true,
+		EnableSha512: true,
+	}
+	var serverURL string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/aggregator.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, aggregatorTemplate, serverURL)
+	})
+	mux.Handle("/", testutil.ProviderHandler(&params, false))
+
+	server := httptest.NewTLSServer(mux)
+	defer server.Close()
+	serverURL = server.URL
+	params.URL = server.URL
+
+	client := util.Client(server.Client())
+
+	for _, tc := range []struct {
+		name    string
+		include []string
+		exclude []string
+		want    bool
+	}{
+		{"all", nil, nil, true},
+		{"included", []string{"ACME"}, nil, true},
+		{"not included", []string{"^Other"}, nil, false},
+		{"excluded", nil, []string{"example\\.com"}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			cfg := config{
+				LogLevel:          &options.LogLevel{Level: slog.LevelError},
+				Directory:         dir,
+				Aggregators:       []string{serverURL + "/aggregator.json"},
+				PreferMirrors:     true,
+				AggregatorInclude: tc.include,
+				AggregatorExclude: tc.exclude,
+			}
+			if err := cfg.prepare(); err != nil {
+				t.Fatal(err)
+			}
+			d, err := newDownloader(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer d.close()
+			d.client = &client
+
+			if err := d.run(context.Background(), nil); err != nil {
+				t.Fatal(err)
+			}
+
+			pmdURL := serverURL + "/provider-metadata.json"
+			if got := d.alternatives[pmdURL]; tc.want &&
+				(len(got) != 2 || got[1] != pmdURL) {
+				t.Errorf("alternatives: got %v, want mirror before %s", got, pmdURL)
+			}
+
+			// The mirror is broken so the advisory has to come from the provider.
+			_, err = os.Stat(filepath.Join(dir, "white", "2020", "avendor-advisory-0004.json"))
+			if got := err == nil; got != tc.want {
+				t.Errorf("advisory downloaded: got %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
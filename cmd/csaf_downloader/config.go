@@ -11,27 +11,35 @@ package main
 import (
 	"crypto/tls"
 	"fmt"
-	"io"
-	"log"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"text/template"
 	"time"
 
 	"github.com/gocsaf/csaf/v3/internal/certs"
 	"github.com/gocsaf/csaf/v3/internal/filter"
 	"github.com/gocsaf/csaf/v3/internal/models"
 	"github.com/gocsaf/csaf/v3/internal/options"
+	"github.com/gocsaf/csaf/v3/internal/truststore"
+	"github.com/gocsaf/csaf/v3/util"
 )
 
 const (
-	defaultWorker         = 2
-	defaultPreset         = "mandatory"
-	defaultForwardQueue   = 5
-	defaultValidationMode = validationStrict
-	defaultLogFile        = "downloader.log"
-	defaultLogLevel       = slog.LevelInfo
+	defaultWorker            = 2
+	defaultPreset            = "mandatory"
+	defaultForwardQueue      = 5
+	defaultValidationMode    = validationStrict
+	defaultLogFile           = "downloader.log"
+	defaultLogLevel          = slog.LevelInfo
+	defaultLogFormat         = options.LogFormatJSON
+	defaultInterval          = time.Hour
+	defaultForwardBackoff    = 30 * time.Second
+	defaultForwardMaxBackoff = time.Hour
+	defaultForwardMaxAge     = 7 * 24 * time.Hour
+	defaultTrustPolicy       = truststore.PolicyTOFU
 )
 
 type validationMode string
@@ -58,13 +66,60 @@ type config struct {
 	Version              bool              `long:"version" description:"Display version of the binary" toml:"-"`
 	NoStore              bool              `long:"no_store" short:"n" description:"Do not store files" toml:"no_store"`
 	Rate                 *float64          `long:"rate" short:"r" description:"The average upper limit of https operations per second (defaults to unlimited)" toml:"rate"`
+	HostRate             *float64          `long:"host_rate" description:"The average upper limit of https operations per second and host (defaults to unlimited)" toml:"host_rate"`
+	HostMaxInFlight      int               `long:"host_max_in_flight" description:"Maximal NUMber of concurrent requests per host (defaults to unlimited)" value-name:"NUM" toml:"host_max_in_flight"`
+	Proxy                string            `long:"proxy" description:"Send the requests through the proxy at URL (http, https or socks5, 'direct' for none, defaults to the environment)" value-name:"URL" toml:"proxy"`
+	Retries              *int              `long:"retries" description:"Retry failed GET and HEAD requests up to NUM times (defaults to 3)" value-name:"NUM" toml:"retries"`
+	RetryDelay           time.Duration     `long:"retry_delay" description:"Wait DURATION before the first retry, doubled with every further retry (defaults to 1s)" value-name:"DURATION" toml:"retry_delay"`
+	RetryMaxDelay        time.Duration     `long:"retry_max_delay" description:"Wait at most DURATION between retries (defaults to 1m)" value-name:"DURATION" toml:"retry_max_delay"`
 	Worker               int               `long:"worker" short:"w" description:"NUMber of concurrent downloads" value-name:"NUM" toml:"worker"`
 	Range                *models.TimeRange `long:"time_range" short:"t" description:"RANGE of time from which advisories to download" value-name:"RANGE" toml:"time_range"`
 	Folder               string            `long:"folder" short:"f" description:"Download into a given subFOLDER" value-name:"FOLDER" toml:"folder"`
+	Layout               string            `long:"layout" description:"Go TEMPLATE of the sub folders to store the advisories in" value-name:"TEMPLATE" toml:"layout"`
+	ProviderTree         string            `long:"provider_tree" description:"Maintain ROLIE feeds, index.txt and changes.csv to serve the advisories like a provider at base URL" value-name:"URL" toml:"provider_tree"`
 	IgnorePattern        []string          `long:"ignore_pattern" short:"i" description:"Do not download files if their URLs match any of the given PATTERNs" value-name:"PATTERN" toml:"ignore_pattern"`
 	ExtraHeader          http.Header       `long:"header" short:"H" description:"One or more extra HTTP header fields" toml:"header"`
 	StreamingROLIEParser bool              `long:"streaming_rolie_parser" description:"Use the streaming ROLIE feed parser (experimental)" toml:"streaming_rolie_parser"`
 
+	FilterProductIdentifiers []string `long:"filter_product_identifier" description:"Only keep advisories with a PURL or CPE starting with any of the given PREFIXes" value-name:"PREFIX" toml:"filter_product_identifiers"`
+	FilterProductNames       []string `long:"filter_product_name" description:"Only keep advisories with a product name matching any of the given PATTERNs" value-name:"PATTERN" toml:"filter_product_names"`
+	FilterCVEs               []string `long:"filter_cve" description:"Only keep advisories mentioning any of the given CVEs" value-name:"CVE" toml:"filter_cves"`
+	FilterCategories         []string `long:"filter_category" description:"Only keep advisories of any of the given document CATEGORYs" value-name:"CATEGORY" toml:"filter_categories"`
+	FilterTLP                []string `long:"filter_tlp" description:"Only keep advisories with any of the given TLP LABELs" value-name:"LABEL" toml:"filter_tlp"`
+	FilterStatus             []string `long:"filter_status" description:"Only keep advisories with any of the given tracking STATUSes" value-name:"STATUS" toml:"filter_status"`
+	FilterMinCVSS            float64  `long:"filter_min_cvss" description:"Only keep advisories with a CVSS base SCORE of at least the given one" value-name:"SCORE" toml:"filter_min_cvss"`
+
+	SyncState string `long:"sync_state" description:"FILE to keep the synchronization state in to only download changed advisories" value-name:"FILE" toml:"sync_state"`
+	FullSync  bool   `long:"full_sync" description:"Ignore the synchronization state and download all advisories" toml:"full_sync"`
+	Index     string `long:"index" description:"SQLite database FILE to index the stored advisories in" value-name:"FILE" toml:"index"`
+	Report    string `long:"report" description:"Write a JSON report of the outcomes of the run to FILE" value-name:"FILE" toml:"report"`
+
+	ArchiveRevisions bool `long:"archive_revisions" description:"Keep superseded revisions of the advisories in a revisions folder" toml:"archive_revisions"`
+
+	//lint:ignore SA5008 We are using choice twice: quarantine, delete
+	Prune       pruneMode `long:"prune" choice:"quarantine" choice:"delete" value-name:"MODE" description:"Quarantine or delete stored advisories no longer listed by the providers or withdrawn" toml:"prune"`
+	PruneDryRun bool      `long:"prune_dry_run" description:"Only report the advisories which would be pruned" toml:"prune_dry_run"`
+
+	TrustStore string `long:"trust_store" description:"FILE to pin the OpenPGP keys of the providers in" value-name:"FILE" toml:"trust_store"`
+	//lint:ignore SA5008 We are using choice more than once: tofu, reject, strict
+	TrustPolicy truststore.Policy `long:"trust_policy" choice:"tofu" choice:"reject" choice:"strict" value-name:"POLICY" description:"POLICY how to treat OpenPGP keys not pinned in the trust store" toml:"trust_policy"`
+
+	Daemon              bool          `long:"daemon" description:"Run as daemon downloading the advisories periodically" toml:"daemon"`
+	Interval            time.Duration `long:"interval" description:"Download the advisories of each domain every DURATION in daemon mode" value-name:"DURATION" toml:"interval"`
+	IntervalAggregators []string      `long:"interval_aggregator" description:"URL of an aggregator.json to take the update intervals of the listed publishers from in daemon mode" value-name:"URL" toml:"interval_aggregators"`
+	HealthAddress       string        `long:"health_address" description:"ADDRESS to serve the /health endpoint on in daemon mode" value-name:"ADDRESS" toml:"health_address"`
+	MetricsAddress      string        `long:"metrics_address" description:"ADDRESS to serve the /metrics endpoint on" value-name:"ADDRESS" toml:"metrics_address"`
+
+	// Hosts are the limits of the requests to the given hosts.
+	Hosts map[string]util.HostLimit `toml:"hosts"`
+	// Proxies are the proxies to reach the given hosts.
+	Proxies map[string]string `toml:"proxies"`
+
+	Aggregators       []string `long:"aggregator" description:"URL of an aggregator.json to download the advisories of all listed providers and publishers from" value-name:"URL" toml:"aggregators"`
+	PreferMirrors     bool     `long:"prefer_mirrors" description:"Prefer the mirrors listed in the aggregators over the original providers" toml:"prefer_mirrors"`
+	AggregatorInclude []string `long:"aggregator_include" description:"Only download from the providers listed in aggregators matching any of the given PATTERNs" value-name:"PATTERN" toml:"aggregator_include"`
+	AggregatorExclude []string `long:"aggregator_exclude" description:"Do not download from the providers listed in aggregators matching any of the given PATTERNs" value-name:"PATTERN" toml:"aggregator_exclude"`
+
 	EnumeratePMDOnly bool `long:"enumerate_pmd_only" description:"If this flag is set to true, the downloader will only enumerate valid provider metadata files, but not download documents" toml:"enumerate_pmd_only"`
 
 	RemoteValidator        string   `long:"validator" description:"URL to validate documents remotely" value-name:"URL" toml:"validator"`
@@ -74,19 +129,50 @@ type config struct {
 	//lint:ignore SA5008 We are using choice twice: strict, unsafe.
 	ValidationMode validationMode `long:"validation_mode" short:"m" choice:"strict" choice:"unsafe" value-name:"MODE" description:"MODE how strict the validation is" toml:"validation_mode"`
 
-	ForwardURL      string      `long:"forward_url" description:"URL of HTTP endpoint to forward downloads to" value-name:"URL" toml:"forward_url"`
-	ForwardHeader   http.Header `long:"forward_header" description:"One or more extra HTTP header fields used by forwarding" toml:"forward_header"`
-	ForwardQueue    int         `long:"forward_queue" description:"Maximal queue LENGTH before forwarder" value-name:"LENGTH" toml:"forward_queue"`
-	ForwardInsecure bool        `long:"forward_insecure" description:"Do not check TLS certificates from forward endpoint" toml:"forward_insecure"`
+	//lint:ignore SA5008 We are using choice more than once: multipart, json, spool, command
+	ForwardTarget   forwardTargetType `long:"forward_target" choice:"multipart" choice:"json" choice:"spool" choice:"command" value-name:"TARGET" description:"TARGET type to forward downloads to" toml:"forward_target"`
+	ForwardTemplate string            `long:"forward_template" description:"Go template FILE to create the JSON documents forwarded by the json target from" value-name:"FILE" toml:"forward_template"`
+	ForwardSpoolDir string            `long:"forward_spool_dir" description:"DIRectory to write the advisories to with the spool target" value-name:"DIR" toml:"forward_spool_dir"`
+	ForwardCommand  []string          `long:"forward_command" description:"Command and its ARGuments to run for every advisory with the command target" value-name:"ARG" toml:"forward_command"`
+	ForwardURL      string            `long:"forward_url" description:"URL of HTTP endpoint to forward downloads to" value-name:"URL" toml:"forward_url"`
+	ForwardHeader   http.Header       `long:"forward_header" description:"One or more extra HTTP header fields used by forwarding" toml:"forward_header"`
+	ForwardQueue    int               `long:"forward_queue" description:"Maximal queue LENGTH before forwarder" value-name:"LENGTH" toml:"forward_queue"`
+	ForwardInsecure bool              `long:"forward_insecure" description:"Do not check TLS certificates from forward endpoint" toml:"forward_insecure"`
+
+	ForwardClientCert       *string  `long:"forward_client_cert" description:"TLS client certificate file (PEM encoded data) used by forwarding" value-name:"CERT-FILE" toml:"forward_client_cert"`
+	ForwardClientKey        *string  `long:"forward_client_key" description:"TLS client private key file (PEM encoded data) used by forwarding" value-name:"KEY-FILE" toml:"forward_client_key"`
+	ForwardClientPassphrase *string  `long:"forward_client_passphrase" description:"Optional passphrase for the client cert used by forwarding (limited, experimental, see doc)" value-name:"PASSPHRASE" toml:"forward_client_passphrase"`
+	ForwardTokenURL         string   `long:"forward_token_url" description:"URL of the OAuth2 token endpoint to fetch bearer tokens for forwarding from" value-name:"URL" toml:"forward_token_url"`
+	ForwardClientID         string   `long:"forward_client_id" description:"OAuth2 client ID used to fetch bearer tokens for forwarding" value-name:"ID" toml:"forward_client_id"`
+	ForwardClientSecret     string   `long:"forward_client_secret" description:"OAuth2 client SECRET used to fetch bearer tokens for forwarding" value-name:"SECRET" toml:"forward_client_secret"`
+	ForwardScopes           []string `long:"forward_scope" description:"One or more OAuth2 SCOPEs to request for the bearer tokens for forwarding" value-name:"SCOPE" toml:"forward_scopes"`
+
+	ForwardQueueFile  string        `long:"forward_queue_file" description:"FILE to persist the queue of advisories to be forwarded in to retry failed forwards" value-name:"FILE" toml:"forward_queue_file"`
+	ForwardBackoff    time.Duration `long:"forward_backoff" description:"Initial DURATION to wait before retrying a failed forward" value-name:"DURATION" toml:"forward_backoff"`
+	ForwardMaxBackoff time.Duration `long:"forward_max_backoff" description:"Maximal DURATION to wait before retrying a failed forward" value-name:"DURATION" toml:"forward_max_backoff"`
+	ForwardMaxAge     time.Duration `long:"forward_max_age" description:"Give up forwarding an advisory after DURATION" value-name:"DURATION" toml:"forward_max_age"`
 
 	LogFile *string `long:"log_file" description:"FILE to log downloading to" value-name:"FILE" toml:"log_file"`
 	//lint:ignore SA5008 We are using choice or than once: debug, info, warn, error
 	LogLevel *options.LogLevel `long:"log_level" description:"LEVEL of logging details" value-name:"LEVEL" choice:"debug" choice:"info" choice:"warn" choice:"error" toml:"log_level"`
+	//lint:ignore SA5008 We are using choice twice: text, json
+	LogFormat options.LogFormat `long:"log_format" description:"FORMAT of the log records" value-name:"FORMAT" choice:"text" choice:"json" toml:"log_format"`
 
 	Config string `short:"c" long:"config" description:"Path to config TOML file" value-name:"TOML-FILE" toml:"-"`
 
 	clientCerts   []tls.Certificate
+	forwardCerts  []tls.Certificate
 	ignorePattern filter.PatternMatcher
+	contentFilter *contentFilter
+
+	aggregatorInclude filter.PatternMatcher
+	aggregatorExclude filter.PatternMatcher
+
+	forwardTemplate *template.Template
+	layout          *template.Template
+	providerTree    *url.URL
+	hostLimiter     *util.HostLimiter
+	proxies         *util.Proxies
 
 	//lint:ignore SA5008 We are using choice or than once: sha256, sha512
 	PreferredHash hashAlgorithm `long:"preferred_hash" choice:"sha256" choice:"sha512" value-name:"HASH" description:"HASH to prefer" toml:"preferred_hash"`
@@ -117,6 +203,12 @@ func parseArgsConfig() ([]string, *config, error) {
 			cfg.ForwardQueue = defaultForwardQueue
 			cfg.LogFile = &logFile
 			cfg.LogLevel = logLevel
+			cfg.LogFormat = defaultLogFormat
+			cfg.Interval = defaultInterval
+			cfg.ForwardBackoff = defaultForwardBackoff
+			cfg.ForwardMaxBackoff = defaultForwardMaxBackoff
+			cfg.ForwardMaxAge = defaultForwardMaxAge
+			cfg.TrustPolicy = defaultTrustPolicy
 		},
 		// Re-establish default values if not set.
 		EnsureDefaults: func(cfg *config) {
@@ -131,12 +223,27 @@ func parseArgsConfig() ([]string, *config, error) {
 			default:
 				cfg.ValidationMode = validationStrict
 			}
+			if cfg.TrustPolicy == "" {
+				cfg.TrustPolicy = defaultTrustPolicy
+			}
 			if cfg.LogFile == nil {
 				cfg.LogFile = &logFile
 			}
 			if cfg.LogLevel == nil {
 				cfg.LogLevel = logLevel
 			}
+			if cfg.LogFormat == "" {
+				cfg.LogFormat = defaultLogFormat
+			}
+			if cfg.Interval <= 0 {
+				cfg.Interval = defaultInterval
+			}
+			if cfg.ForwardBackoff <= 0 {
+				cfg.ForwardBackoff = defaultForwardBackoff
+			}
+			if cfg.ForwardMaxBackoff < cfg.ForwardBackoff {
+				cfg.ForwardMaxBackoff = max(cfg.ForwardBackoff, defaultForwardMaxBackoff)
+			}
 		},
 	}
 	return p.Parse()
@@ -173,6 +280,87 @@ func (cfg *config) verbose() bool {
 	return cfg.LogLevel.Level <= slog.LevelDebug
 }
 
+// prepareHostLimits creates the limiter of the requests per host if needed.
+func (cfg *config) prepareHostLimits() error {
+	var def util.HostLimit
+	if cfg.HostRate != nil {
+		def.Rate = *cfg.HostRate
+	}
+	def.MaxInFlight = cfg.HostMaxInFlight
+	if def == (util.HostLimit{}) && len(cfg.Hosts) == 0 {
+		return nil
+	}
+	if def.Rate < 0 || def.MaxInFlight < 0 {
+		return fmt.Errorf("invalid host limits: rate %g, max in flight %d", def.Rate, def.MaxInFlight)
+	}
+	for host, l := range cfg.Hosts {
+		if l.Rate < 0 || l.MaxInFlight < 0 {
+			return fmt.Errorf("invalid limits of host %q", host)
+		}
+	}
+	cfg.hostLimiter = util.NewHostLimiter(def, cfg.Hosts)
+	return nil
+}
+
+// prepareProxies sets up the proxies if configured.
+func (cfg *config) prepareProxies() error {
+	if cfg.Proxy == "" && len(cfg.Proxies) == 0 {
+		return nil
+	}
+	proxies, err := util.NewProxies(cfg.Proxy, cfg.Proxies)
+	if err != nil {
+		return err
+	}
+	cfg.proxies = proxies
+	return nil
+}
+
+// retries returns the number of retries of failed requests.
+func (cfg *config) retries() int {
+	if cfg.Retries == nil {
+		return util.DefaultRetries
+	}
+	return max(*cfg.Retries, 0)
+}
+
+// inDirectory resolves relative paths against the download directory.
+func (cfg *config) inDirectory(fname string) string {
+	if filepath.IsAbs(fname) {
+		return fname
+	}
+	return filepath.Join(cfg.Directory, fname)
+}
+
+// syncStateFile returns the path of the file of the synchronization state.
+// Relative paths are resolved against the download directory.
+func (cfg *config) syncStateFile() string {
+	return cfg.inDirectory(cfg.SyncState)
+}
+
+// forwardQueueFile returns the path of the persistent forward queue.
+// Relative paths are resolved against the download directory.
+func (cfg *config) forwardQueueFile() string {
+	return cfg.inDirectory(cfg.ForwardQueueFile)
+}
+
+// indexFile returns the path of the database of the advisory index.
+// Relative paths are resolved against the download directory.
+func (cfg *config) indexFile() string {
+	return cfg.inDirectory(cfg.Index)
+}
+
+// trustStoreFile returns the path of the OpenPGP trust store.
+// Relative paths are resolved against the download directory.
+func (cfg *config) trustStoreFile() string {
+	return cfg.inDirectory(cfg.TrustStore)
+}
+
+// reportFile returns the path of the run report.
+// Relative paths are resolved against the download directory.
+func (cfg *config) reportFile() string {
+	return cfg.inDirectory(cfg.Report)
+}
+
 // prepareDirectory ensures that the working directory
 // exists and is setup properly.
 func (cfg *config) prepareDirectory() error {
@@ -210,35 +398,24 @@ func dropSubSeconds(_ []string, a slog.Attr) slog.Attr {
 
 // prepareLogging sets up the structured logging.
 func (cfg *config) prepareLogging() error {
-	var w io.Writer
+	lg := options.Logging{
+		Format:      cfg.LogFormat,
+		Level:       cfg.LogLevel.Level,
+		ReplaceAttr: dropSubSeconds,
+	}
 	if cfg.LogFile == nil || *cfg.LogFile == "" {
-		log.Println("using STDERR for logging")
-		w = os.Stderr
+		slog.Info("Logging to STDERR")
 	} else {
-		var fname string
 		// We put the log inside the download folder
 		// if it is not absolute.
 		if filepath.IsAbs(*cfg.LogFile) {
-			fname = *cfg.LogFile
+			lg.File = *cfg.LogFile
 		} else {
-			fname = filepath.Join(cfg.Directory, *cfg.LogFile)
+			lg.File = filepath.Join(cfg.Directory, *cfg.LogFile)
 		}
-		f, err := os.OpenFile(fname, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
-		if err != nil {
-			return err
-		}
-		log.Printf("using %q for logging\n", fname)
-		w = f
-	}
-	ho := slog.HandlerOptions{
-		// AddSource: true,
-		Level:       cfg.LogLevel.Level,
-		ReplaceAttr: dropSubSeconds,
+		slog.Info("Logging to file", "file", lg.File)
 	}
-	handler := slog.NewJSONHandler(w, &ho)
-	logger := slog.New(handler)
-	slog.SetDefault(logger)
-	return nil
+	return lg.SetDefault()
 }
 
 // compileIgnorePatterns compiles the configure patterns to be ignored.
@@ -251,6 +428,31 @@ func (cfg *config) compileIgnorePatterns() error {
 	return nil
 }
 
+// compileAggregatorPatterns compiles the patterns to filter
+// the providers listed in aggregators.
+func (cfg *config) compileAggregatorPatterns() error {
+	include, err := filter.NewPatternMatcher(cfg.AggregatorInclude)
+	if err != nil {
+		return err
+	}
+	exclude, err := filter.NewPatternMatcher(cfg.AggregatorExclude)
+	if err != nil {
+		return err
+	}
+	cfg.aggregatorInclude, cfg.aggregatorExclude = include, exclude
+	return nil
+}
+
+// compileContentFilter compiles the configured content filters.
+func (cfg *config) compileContentFilter() error {
+	cf, err := newContentFilter(cfg)
+	if err != nil {
+		return err
+	}
+	cfg.contentFilter = cf
+	return nil
+}
+
 // prepareCertificates loads the client side certificates used by the HTTP client.
 func (cfg *config) prepareCertificates() error {
 	cert, err := certs.LoadCertificate(
@@ -259,6 +461,11 @@ func (cfg *config) prepareCertificates() error {
 		return err
 	}
 	cfg.clientCerts = cert
+	if cert, err = certs.LoadCertificate(
+		cfg.ForwardClientCert, cfg.ForwardClientKey, cfg.ForwardClientPassphrase); err != nil {
+		return fmt.Errorf("loading forward client certificate failed: %w", err)
+	}
+	cfg.forwardCerts = cert
 	return nil
 }
 
@@ -269,6 +476,13 @@ func (cfg *config) prepare() error {
 		(*config).prepareLogging,
 		(*config).prepareCertificates,
 		(*config).compileIgnorePatterns,
+		(*config).compileAggregatorPatterns,
+		(*config).compileLayout,
+		(*config).prepareProviderTree,
+		(*config).prepareHostLimits,
+		(*config).prepareProxies,
+		(*config).compileContentFilter,
+		(*config).prepareForwarding,
 	} {
 		if err := prepare(cfg); err != nil {
 			return err
@@ -0,0 +1,162 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gocsaf/csaf/v3/internal/filter"
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+// contentFilter decides on the content of an advisory
+// if it should be kept. All configured criteria have to match.
+// A criterion given as list matches if any of its entries matches.
+type contentFilter struct {
+	identifiers []string
+	names       filter.PatternMatcher
+	cves        []string
+	categories  []string
+	tlps        []string
+	status      []string
+	minCVSS     float64
+}
+
+// newContentFilter creates a content filter from the configuration.
+// It returns nil if no filtering is configured.
+func newContentFilter(cfg *config) (*contentFilter, error) {
+	if len(cfg.FilterProductIdentifiers) == 0 &&
+		len(cfg.FilterProductNames) == 0 &&
+		len(cfg.FilterCVEs) == 0 &&
+		len(cfg.FilterCategories) == 0 &&
+		len(cfg.FilterTLP) == 0 &&
+		len(cfg.FilterStatus) == 0 &&
+		cfg.FilterMinCVSS <= 0 {
+		return nil, nil
+	}
+	if cfg.FilterMinCVSS > 10 {
+		return nil, fmt.Errorf("minimal CVSS base score %.1f out of range [0, 10]", cfg.FilterMinCVSS)
+	}
+	names, err := filter.NewPatternMatcher(cfg.FilterProductNames)
+	if err != nil {
+		return nil, err
+	}
+	lower := func(s []string) []string {
+		l := make([]string, len(s))
+		for i, x := range s {
+			l[i] = strings.ToLower(x)
+		}
+		return l
+	}
+	return &contentFilter{
+		identifiers: cfg.FilterProductIdentifiers,
+		names:       names,
+		cves:        lower(cfg.FilterCVEs),
+		categories:  lower(cfg.FilterCategories),
+		tlps:        lower(cfg.FilterTLP),
+		status:      lower(cfg.FilterStatus),
+		minCVSS:     cfg.FilterMinCVSS,
+	}, nil
+}
+
+// filterStrings returns the strings found in doc by the given expressions.
+func filterStrings(expr *util.PathEval, doc any, exprs ...string) []string {
+	var all []string
+	for _, e := range exprs {
+		x, err := expr.Eval(e, doc)
+		if err != nil {
+			continue
+		}
+		if s, ok := x.(string); ok {
+			all = append(all, s)
+		} else if strs, ok := util.AsStrings(x); ok {
+			all = append(all, strs...)
+		}
+	}
+	return all
+}
+
+// containsFold checks if any of the strings is in the given
+// list of lower case strings ignoring the case.
+func containsFold(list []string, strs []string) bool {
+	return slices.ContainsFunc(strs, func(s string) bool {
+		return slices.Contains(list, strings.ToLower(s))
+	})
+}
+
+// reject checks if the given advisory should be dropped.
+// It returns the reason for the rejection or
+// an empty string if the advisory should be kept.
+func (cf *contentFilter) reject(expr *util.PathEval, doc any) string {
+	if cf == nil {
+		return ""
+	}
+	if len(cf.categories) > 0 &&
+		!containsFold(cf.categories, filterStrings(expr, doc, `$.document.category`)) {
+		return "category"
+	}
+	if len(cf.tlps) > 0 &&
+		!containsFold(cf.tlps, filterStrings(expr, doc, `$.document.distribution.tlp.label`)) {
+		return "tlp"
+	}
+	if len(cf.status) > 0 &&
+		!containsFold(cf.status, filterStrings(expr, doc, `$.document.tracking.status`)) {
+		return "tracking_status"
+	}
+	if len(cf.cves) > 0 &&
+		!containsFold(cf.cves, filterStrings(expr, doc, `$.vulnerabilities[*].cve`)) {
+		return "cve"
+	}
+	if len(cf.identifiers) > 0 &&
+		!slices.ContainsFunc(filterStrings(expr, doc,
+			`$.product_tree..product_identification_helper.purl`,
+			`$.product_tree..product_identification_helper.purls[*]`,
+			`$.product_tree..product_identification_helper.cpe`,
+		), func(id string) bool {
+			return slices.ContainsFunc(cf.identifiers, func(prefix string) bool {
+				return strings.HasPrefix(id, prefix)
+			})
+		}) {
+		return "product_identifier"
+	}
+	if len(cf.names) > 0 &&
+		!slices.ContainsFunc(filterStrings(expr, doc, `$.product_tree..name`), cf.names.Matches) {
+		return "product_name"
+	}
+	if cf.minCVSS > 0 && maxBaseScore(expr, doc) < cf.minCVSS {
+		return "cvss"
+	}
+	return ""
+}
+
+// maxBaseScore returns the highest CVSS base score of the given advisory.
+func maxBaseScore(expr *util.PathEval, doc any) float64 {
+	var score float64
+	for _, e := range []string{
+		`$.vulnerabilities[*].scores[*].cvss_v2.baseScore`,
+		`$.vulnerabilities[*].scores[*].cvss_v3.baseScore`,
+		`$.vulnerabilities[*].metrics[*].content.cvss_v2.baseScore`,
+		`$.vulnerabilities[*].metrics[*].content.cvss_v3.baseScore`,
+		`$.vulnerabilities[*].metrics[*].content.cvss_v4.baseScore`,
+	} {
+		x, err := expr.Eval(e, doc)
+		if err != nil {
+			continue
+		}
+		scores, _ := x.([]any)
+		for _, s := range scores {
+			if f, ok := s.(float64); ok && f > score {
+				score = f
+			}
+		}
+	}
+	return score
+}
@@ -0,0 +1,98 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+const contentFilterDoc = `{
+  "document": {
+    "category": "csaf_security_advisory",
+    "distribution": { "tlp": { "label": "WHITE" } },
+    "tracking": { "status": "final" }
+  },
+  "product_tree": {
+    "branches": [{
+      "category": "vendor",
+      "name": "Example Company",
+      "branches": [{
+        "category": "product_name",
+        "name": "Example Product",
+        "product": {
+          "name": "Example Product 1.0",
+          "product_id": "CSAFPID-0001",
+          "product_identification_helper": {
+            "purl": "pkg:npm/example@1.0",
+            "cpe": "cpe:/a:example:product:1.0"
+          }
+        }
+      }]
+    }]
+  },
+  "vulnerabilities": [{
+    "cve": "CVE-2026-0001",
+    "scores": [{ "cvss_v3": { "baseScore": 7.5 } }]
+  }]
+}`
+
+func TestContentFilter(t *testing.T) {
+	var doc any
+	if err := json.Unmarshal([]byte(contentFilterDoc), &doc); err != nil {
+		t.Fatal(err)
+	}
+	expr := util.NewPathEval()
+
+	for _, test := range []struct {
+		name string
+		cfg  config
+		want string
+	}{
+		{name: "none"},
+		{name: "category", cfg: config{FilterCategories: []string{"csaf_vex"}}, want: "category"},
+		{name: "category match", cfg: config{FilterCategories: []string{"csaf_vex", "CSAF_security_advisory"}}},
+		{name: "tlp", cfg: config{FilterTLP: []string{"red"}}, want: "tlp"},
+		{name: "tlp match", cfg: config{FilterTLP: []string{"white"}}},
+		{name: "status", cfg: config{FilterStatus: []string{"draft"}}, want: "tracking_status"},
+		{name: "cve", cfg: config{FilterCVEs: []string{"CVE-2026-0002"}}, want: "cve"},
+		{name: "cve match", cfg: config{FilterCVEs: []string{"cve-2026-0001"}}},
+		{name: "purl", cfg: config{FilterProductIdentifiers: []string{"pkg:npm/other"}}, want: "product_identifier"},
+		{name: "purl match", cfg: config{FilterProductIdentifiers: []string{"pkg:npm/example"}}},
+		{name: "cpe match", cfg: config{FilterProductIdentifiers: []string{"cpe:/a:example:"}}},
+		{name: "name", cfg: config{FilterProductNames: []string{"^Other"}}, want: "product_name"},
+		{name: "name match", cfg: config{FilterProductNames: []string{"^Example Product"}}},
+		{name: "cvss", cfg: config{FilterMinCVSS: 9}, want: "cvss"},
+		{name: "cvss match", cfg: config{FilterMinCVSS: 7.5}},
+		{
+			name: "combined",
+			cfg: config{
+				FilterCategories: []string{"csaf_security_advisory"},
+				FilterMinCVSS:    8,
+			},
+			want: "cvss",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			cf, err := newContentFilter(&test.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := cf.reject(expr, doc); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+
+	if _, err := newContentFilter(&config{FilterMinCVSS: 11}); err == nil {
+		t.Error("expected error for out of range CVSS score")
+	}
+}
@@ -0,0 +1,449 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+const (
+	// day is the duration of a day.
+	day = 24 * time.Hour
+	// healthShutdownTimeout is the time given to the health
+	// endpoint to finish its requests on shutdown.
+	healthShutdownTimeout = 5 * time.Second
+)
+
+// namedIntervals are the update intervals
+// which can be expressed as a single word.
+var namedIntervals = map[string]time.Duration{
+	"hourly":  time.Hour,
+	"daily":   day,
+	"weekly":  7 * day,
+	"monthly": 30 * day,
+}
+
+// everyInterval matches update intervals like "every 4 hours".
+var everyInterval = regexp.MustCompile(`^every\s+(\d+\s*)?(minute|hour|day|week|month)s?$`)
+
+// intervalUnits are the units of intervals like "every 4 hours".
+var intervalUnits = map[string]time.Duration{
+	"minute": time.Minute,
+	"hour":   time.Hour,
+	"day":    day,
+	"week":   7 * day,
+	"month":  30 * day,
+}
+
+// parseUpdateInterval tries to interpret the free text
+// 'update_interval' of a publisher listed in an aggregator.
+// The boolean is false if the text cannot be interpreted.
+func parseUpdateInterval(s string) (time.Duration, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if d, ok := namedIntervals[s]; ok {
+		return d, true
+	}
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return d, true
+	}
+	if m := everyInterval.FindStringSubmatch(s); m != nil {
+		n := 1
+		if m[1] != "" {
+			var err error
+			if n, err = strconv.Atoi(strings.TrimSpace(m[1])); err != nil || n < 1 {
+				return 0, false
+			}
+		}
+		return time.Duration(n) * intervalUnits[m[2]], true
+	}
+	return 0, false
+}
+
+// loadUpdateIntervals loads the given aggregators and extracts
+// the update intervals of the listed publishers by the URLs
+// of their provider-metadata.json files.
+func loadUpdateIntervals(
+	ctx context.Context,
+	client util.ClientWithContext,
+	aggregators []string,
+) map[string]time.Duration {
+	intervals := map[string]time.Duration{}
+	for _, u := range aggregators {
+		agg, err := loadAggregator(ctx, client, u)
+		if err != nil {
+			slog.Warn("Loading aggregator failed",
+				"url", u,
+				"error", err)
+			continue
+		}
+		for _, pub := range agg.CSAFPublishers {
+			if pub == nil || pub.Metadata == nil || pub.Metadata.URL == nil {
+				continue
+			}
+			if d, ok := parseUpdateInterval(pub.UpdateInterval); ok {
+				intervals[string(*pub.Metadata.URL)] = d
+			} else {
+				slog.Debug("Cannot interpret update interval",
+					"url", *pub.Metadata.URL,
+					"update_interval", pub.UpdateInterval)
+			}
+		}
+	}
+	return intervals
+}
+
+// domainStatus is the state of a domain in daemon mode.
+type domainStatus struct {
+	Domain      string    `json:"domain"`
+	LastRun     time.Time `json:"last_run,omitzero"`
+	LastSuccess time.Time `json:"last_success,omitzero"`
+	LastError   string    `json:"last_error,omitempty"`
+	NextRun     time.Time `json:"next_run,omitzero"`
+	Interval    string    `json:"interval,omitempty"`
+}
+
+// health collects the state of the daemon and
+// serves it as the health endpoint.
+type health struct {
+	mu       sync.Mutex
+	started  time.Time
+	stopping bool
+	domains  map[string]*domainStatus
+}
+
+// newHealth creates a new health state.
+func newHealth() *health {
+	return &health{
+		started: time.Now().UTC(),
+		domains: map[string]*domainStatus{},
+	}
+}
+
+// status returns the state of the given domain.
+// Needs to be called with the lock held.
+func (h *health) status(domain string) *domainStatus {
+	ds := h.domains[domain]
+	if ds == nil {
+		ds = &domainStatus{Domain: domain}
+		h.domains[domain] = ds
+	}
+	return ds
+}
+
+// scheduled records the next run of a domain.
+func (h *health) scheduled(domain string, next time.Time, interval time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ds := h.status(domain)
+	ds.NextRun = next.UTC()
+	ds.Interval = interval.String()
+}
+
+// finished records the result of a run of a domain.
+func (h *health) finished(domain string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ds := h.status(domain)
+	ds.LastRun = time.Now().UTC()
+	if err != nil {
+		ds.LastError = err.Error()
+	} else {
+		ds.LastError = ""
+		ds.LastSuccess = ds.LastRun
+	}
+}
+
+// keep drops the states of the domains not in the given list.
+func (h *health) keep(domains []string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for domain := range h.domains {
+		if !slices.Contains(domains, domain) {
+			delete(h.domains, domain)
+		}
+	}
+}
+
+// stop marks the daemon as shutting down.
+func (h *health) stop() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.stopping = true
+}
+
+// ServeHTTP implements [http.Handler].
+func (h *health) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	h.mu.Lock()
+	report := struct {
+		Status  string          `json:"status"`
+		Started time.Time       `json:"started"`
+		Domains []*domainStatus `json:"domains"`
+	}{
+		Status:  "ok",
+		Started: h.started,
+	}
+	code := http.StatusOK
+	for _, ds := range h.domains {
+		if ds.LastError != "" {
+			report.Status = "degraded"
+		}
+		copied := *ds
+		report.Domains = append(report.Domains, &copied)
+	}
+	if h.stopping {
+		report.Status = "stopping"
+		code = http.StatusServiceUnavailable
+	}
+	h.mu.Unlock()
+
+	slices.SortFunc(report.Domains, func(a, b *domainStatus) int {
+		return strings.Compare(a.Domain, b.Domain)
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if r.Method == http.MethodGet {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(&report); err != nil {
+			slog.Error("Writing health report failed", "error", err)
+		}
+	}
+}
+
+// interval returns the polling interval of the given domain.
+func (d *downloader) interval(domain string, intervals map[string]time.Duration) time.Duration {
+	// Domains taken from aggregators are the URLs of the provider-metadata.json.
+	if iv, ok := intervals[domain]; ok {
+		return iv
+	}
+	if pmdURL, ok := d.pmdURLs[domain]; ok {
+		if iv, ok := intervals[pmdURL]; ok {
+			return iv
+		}
+	}
+	return d.cfg.Interval
+}
+
+// runDaemon downloads the advisories of the given domains
+// periodically till the context is cancelled.
+func (d *downloader) runDaemon(ctx context.Context, domains []string, h *health) error {
+	given := domains
+	domains = d.addAggregatorDomains(ctx, given)
+
+	// The aggregators may be unreachable or list no matching
+	// providers. Try again later instead of spinning.
+	for len(domains) == 0 {
+		slog.Warn("No domains to download, reloading aggregators later",
+			"interval", d.cfg.Interval)
+		timer := time.NewTimer(d.cfg.Interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+		domains = d.addAggregatorDomains(ctx, given)
+	}
+	resolved := time.Now()
+
+	intervals := loadUpdateIntervals(ctx, d.httpClient(),
+		append(slices.Clone(d.cfg.IntervalAggregators), d.cfg.Aggregators...))
+
+	// schedule lets new domains run now and forgets about removed ones.
+	next := make(map[string]time.Time, len(domains))
+	schedule := func(now time.Time) {
+		for domain := range next {
+			if !slices.Contains(domains, domain) {
+				delete(next, domain)
+			}
+		}
+		for _, domain := range domains {
+			if _, ok := next[domain]; !ok {
+				next[domain] = now
+				h.scheduled(domain, now, d.interval(domain, intervals))
+			}
+		}
+		h.keep(domains)
+	}
+	schedule(resolved)
+
+	for {
+		// Find the domain which is due next.
+		var domain string
+		for _, dom := range domains {
+			if domain == "" || next[dom].Before(next[domain]) {
+				domain = dom
+			}
+		}
+
+		if wait := time.Until(next[domain]); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil
+			case <-timer.C:
+			}
+		}
+
+		err := d.download(ctx, domain)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			slog.Error("Downloading failed",
+				"domain", domain,
+				"error", err)
+		}
+		h.finished(domain, err)
+
+		if err := d.prune(domains); err != nil {
+			slog.Error("Pruning failed", "error", err)
+		}
+		if err := d.writeProviderTree(); err != nil {
+			slog.Error("Updating provider tree failed", "error", err)
+		}
+
+		d.statsMu.Lock()
+		d.stats.log()
+		d.stats = stats{}
+		d.statsMu.Unlock()
+
+		// Each download gets its own report.
+		d.writeReport()
+		d.report = newRunReport(d.cfg)
+
+		iv := d.interval(domain, intervals)
+		next[domain] = time.Now().Add(iv)
+		h.scheduled(domain, next[domain], iv)
+		slog.Info("Next download scheduled",
+			"domain", domain,
+			"at", next[domain])
+
+		// Pick up the changes of the providers listed in the aggregators.
+		// Keep the known domains if an aggregator cannot be loaded.
+		if len(d.cfg.Aggregators) > 0 && time.Since(resolved) >= d.cfg.Interval {
+			resolved = time.Now()
+			if nDomains := d.addAggregatorDomains(ctx, given); !d.aggregatorFailed && len(nDomains) > 0 {
+				domains = nDomains
+				intervals = loadUpdateIntervals(ctx, d.httpClient(),
+					append(slices.Clone(d.cfg.IntervalAggregators), d.cfg.Aggregators...))
+				schedule(resolved)
+			}
+		}
+	}
+}
+
+// serveHealth starts the health endpoint if configured.
+// The returned function shuts it down.
+func serveHealth(cfg *config, h *health) func() {
+	if cfg.HealthAddress == "" {
+		return func() {}
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/health", h)
+	srv := &http.Server{
+		Addr:              cfg.HealthAddress,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Serving health endpoint failed", "error", err)
+		}
+	}()
+	slog.Info("Serving health endpoint", "address", cfg.HealthAddress)
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), healthShutdownTimeout)
+		defer cancel()
+		srv.Shutdown(ctx)
+	}
+}
+
+// runAsDaemon runs the downloader in daemon mode.
+// On request the configuration is reloaded.
+func runAsDaemon(cfg *config, domains []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), terminationSignals...)
+	defer stop()
+
+	reload := make(chan os.Signal, 1)
+	notifyReload(reload)
+	defer signal.Stop(reload)
+
+	h := newHealth()
+	defer serveHealth(cfg, h)()
+
+	m := newDownloaderMetrics(cfg)
+	defer m.serve(cfg)()
+
+	for {
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan error, 1)
+		go func() {
+			done <- withDownloader(cfg, m, func(d *downloader) error {
+				return d.runDaemon(runCtx, domains, h)
+			})
+		}()
+
+		select {
+		case err := <-done:
+			cancel()
+			h.stop()
+			return err
+		case <-reload:
+			slog.Info("Reloading configuration")
+			cancel()
+			if err := <-done; err != nil {
+				return err
+			}
+			nDomains, nCfg, err := parseArgsConfig()
+			if err == nil {
+				err = nCfg.prepare()
+			}
+			if err != nil {
+				slog.Error("Reloading configuration failed, keeping old one", "error", err)
+				continue
+			}
+			// The endpoints are served for the whole lifetime of the daemon.
+			if nCfg.HealthAddress != cfg.HealthAddress {
+				slog.Warn("Changing the health address needs a restart",
+					"address", cfg.HealthAddress)
+				nCfg.HealthAddress = cfg.HealthAddress
+			}
+			if nCfg.MetricsAddress != cfg.MetricsAddress {
+				slog.Warn("Changing the metrics address needs a restart",
+					"address", cfg.MetricsAddress)
+				nCfg.MetricsAddress = cfg.MetricsAddress
+			}
+			cfg = nCfg
+			if len(nDomains) > 0 {
+				domains = nDomains
+			}
+		}
+	}
+}
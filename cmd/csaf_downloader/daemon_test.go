@@ -0,0 +1,215 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gocsaf/csaf/v3/internal/options"
+	"github.com/gocsaf/csaf/v3/internal/testutil"
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+func TestParseUpdateInterval(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want time.Duration
+		ok   bool
+	}{
+		{in: "daily", want: 24 * time.Hour, ok: true},
+		{in: " Hourly ", want: time.Hour, ok: true},
+		{in: "every 4 hours", want: 4 * time.Hour, ok: true},
+		{in: "every week", want: 7 * 24 * time.Hour, ok: true},
+		{in: "30m", want: 30 * time.Minute, ok: true},
+		{in: "every 0 days"},
+		{in: "-1h"},
+		{in: "whenever we feel like it"},
+		{in: ""},
+	} {
+		got, ok := parseUpdateInterval(test.in)
+		if ok != test.ok || got != test.want {
+			t.Errorf("%q: got %v/%t, want %v/%t", test.in, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestHealth(t *testing.T) {
+	h := newHealth()
+	h.scheduled("b.example.com", time.Now(), time.Hour)
+	h.finished("b.example.com", nil)
+	h.finished("a.example.com", errors.New("boom"))
+
+	get := func() (int, string, []string) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+		var report struct {
+			Status  string `json:"status"`
+			Domains []struct {
+				Domain string `json:"domain"`
+			} `json:"domains"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+			t.Fatal(err)
+		}
+		var domains []string
+		for _, d := range report.Domains {
+			domains = append(domains, d.Domain)
+		}
+		return rec.Code, report.Status, domains
+	}
+
+	code, status, domains := get()
+	if code != http.StatusOK || status != "degraded" {
+		t.Errorf("got %d/%s, want 200/degraded", code, status)
+	}
+	if len(domains) != 2 || domains[0] != "a.example.com" || domains[1] != "b.example.com" {
+		t.Errorf("unexpected domains %v", domains)
+	}
+
+	h.stop()
+	if code, status, _ := get(); code != http.StatusServiceUnavailable || status != "stopping" {
+		t.Errorf("got %d/%s, want 503/stopping", code, status)
+	}
+}
+
+func TestDaemonWithoutDomains(t *testing.T) {
+	var loads atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		loads.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	noRetries := 0
+	cfg := config{
+		LogLevel:    &options.LogLevel{Level: slog.LevelError + 1},
+		Directory:   t.TempDir(),
+		Aggregators: []string{server.URL + "/aggregator.json"},
+		Interval:    20 * time.Millisecond,
+		Retries:     &noRetries,
+	}
+	if err := cfg.prepare(); err != nil {
+		t.Fatal(err)
+	}
+	d, err := newDownloader(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.close()
+	client := util.Client(server.Client())
+	d.client = &client
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	h := newHealth()
+	done := make(chan error)
+	go func() { done <- d.runDaemon(ctx, nil, h) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("daemon did not stop")
+	}
+
+	if n := loads.Load(); n < 2 {
+		t.Errorf("aggregator loaded %d times, want it to be reloaded", n)
+	}
+	if len(h.domains) != 0 {
+		t.Errorf("downloads attempted without domains: %v", h.domains)
+	}
+}
+
+func TestDaemonRefreshesAggregators(t *testing.T) {
+	params := testutil.ProviderParams{
+		EnableSha256: true,
+		EnableSha512: true,
+	}
+	provider := testutil.ProviderHandler(&params, false)
+
+	// The aggregator lists the second provider after its first load.
+	var loads atomic.Int32
+	var first, second *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/aggregator.json", func(w http.ResponseWriter, _ *http.Request) {
+		listed := first.URL
+		if loads.Add(1) > 1 {
+			listed = second.URL
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, aggregatorTemplate, listed)
+	})
+	mux.Handle("/", provider)
+	first = httptest.NewTLSServer(mux)
+	defer first.Close()
+	second = httptest.NewTLSServer(provider)
+	defer second.Close()
+	params.URL = first.URL
+
+	noRetries := 0
+	cfg := config{
+		LogLevel:    &options.LogLevel{Level: slog.LevelError + 1},
+		Directory:   t.TempDir(),
+		Aggregators: []string{first.URL + "/aggregator.json"},
+		Interval:    20 * time.Millisecond,
+		Retries:     &noRetries,
+	}
+	if err := cfg.prepare(); err != nil {
+		t.Fatal(err)
+	}
+	d, err := newDownloader(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.close()
+	client := util.Client(first.Client())
+	d.client = &client
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h := newHealth()
+	done := make(chan error)
+	go func() { done <- d.runDaemon(ctx, nil, h) }()
+
+	// Wait for the newly listed provider to be downloaded.
+	pmdURL := second.URL + "/provider-metadata.json"
+	downloaded := func() bool {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		ds := h.domains[pmdURL]
+		return ds != nil && !ds.LastRun.IsZero()
+	}
+	for deadline := time.Now().Add(5 * time.Second); !downloaded(); {
+		if time.Now().After(deadline) {
+			t.Fatal("newly listed provider was not downloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := h.domains[first.URL+"/provider-metadata.json"]; ok {
+		t.Error("provider no longer listed is still scheduled")
+	}
+}
@@ -36,7 +36,9 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/gocsaf/csaf/v3/csaf"
+	"github.com/gocsaf/csaf/v3/internal/metrics"
 	"github.com/gocsaf/csaf/v3/internal/misc"
+	"github.com/gocsaf/csaf/v3/internal/truststore"
 	"github.com/gocsaf/csaf/v3/util"
 )
 
@@ -53,9 +55,22 @@ type downloader struct {
 	keys      *crypto.KeyRing
 	validator csaf.RemoteValidatorWithContext
 	forwarder *forwarder
-	mkdirMu   sync.Mutex
-	statsMu   sync.Mutex
-	stats     stats
+	state     *syncState
+	trust     *truststore.Store
+	index     *advisoryIndex
+	report    *runReport
+	pmdURLs   map[string]string
+	listings  map[string]map[string]bool
+	// alternatives are the URLs to load the
+	// provider-metadata.json of a domain from.
+	alternatives map[string][]string
+	// aggregatorFailed is set if not all aggregators could be
+	// loaded. The list of domains is incomplete then.
+	aggregatorFailed bool
+	mkdirMu          sync.Mutex
+	statsMu          sync.Mutex
+	stats            stats
+	metrics          *downloaderMetrics
 }
 
 // failedValidationDir is the name of the sub folder
@@ -63,6 +78,29 @@ type downloader struct {
 // unsafe mode.
 const failedValidationDir = "failed_validation"
 
+// downloadGrace is the time an advisory download is given
+// to finish after the downloader was asked to stop.
+const downloadGrace = 10 * time.Second
+
+// withGrace returns a context which is cancelled the given
+// grace period after ctx is cancelled.
+func withGrace(ctx context.Context, grace time.Duration) (context.Context, context.CancelFunc) {
+	graceCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		timer := time.NewTimer(grace)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			cancel()
+		case <-graceCtx.Done():
+		}
+	})
+	return graceCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
 func newDownloader(cfg *config) (*downloader, error) {
 	var validator csaf.RemoteValidatorWithContext
 
@@ -80,9 +118,55 @@ func newDownloader(cfg *config) (*downloader, error) {
 		validator = csaf.SynchronizedRemoteValidatorWithContext(validator)
 	}
 
+	var trust *truststore.Store
+	if cfg.TrustStore != "" {
+		var err error
+		if trust, err = truststore.Open(cfg.trustStoreFile(), cfg.TrustPolicy); err != nil {
+			if validator != nil {
+				validator.Close()
+			}
+			return nil, fmt.Errorf(
+				"opening trust store failed: %w", err)
+		}
+	}
+
+	var state *syncState
+	if cfg.SyncState != "" {
+		var err error
+		if state, err = openSyncState(cfg.syncStateFile()); err != nil {
+			if validator != nil {
+				validator.Close()
+			}
+			return nil, fmt.Errorf(
+				"opening synchronization state failed: %w", err)
+		}
+	}
+
+	var index *advisoryIndex
+	if cfg.Index != "" && !cfg.NoStore {
+		var err error
+		if index, err = openAdvisoryIndex(cfg.indexFile()); err != nil {
+			if validator != nil {
+				validator.Close()
+			}
+			if state != nil {
+				state.close()
+			}
+			return nil, fmt.Errorf(
+				"opening advisory index failed: %w", err)
+		}
+	}
+
 	return &downloader{
-		cfg:       cfg,
-		validator: validator,
+		cfg:          cfg,
+		validator:    validator,
+		state:        state,
+		trust:        trust,
+		index:        index,
+		report:       newRunReport(cfg),
+		pmdURLs:      map[string]string{},
+		listings:     map[string]map[string]bool{},
+		alternatives: map[string][]string{},
 	}, nil
 }
 
@@ -91,6 +175,18 @@ func (d *downloader) close() {
 		d.validator.Close()
 		d.validator = nil
 	}
+	if d.state != nil {
+		if err := d.state.close(); err != nil {
+			slog.Error("Closing synchronization state failed", "error", err)
+		}
+		d.state = nil
+	}
+	if d.index != nil {
+		if err := d.index.close(); err != nil {
+			slog.Error("Closing advisory index failed", "error", err)
+		}
+		d.index = nil
+	}
 }
 
 // addStats add stats to total stats
@@ -100,6 +196,13 @@ func (d *downloader) addStats(o *stats) {
 	d.stats.add(o)
 }
 
+// totalFailed returns the number of failed downloads so far.
+func (d *downloader) totalFailed() int {
+	d.statsMu.Lock()
+	defer d.statsMu.Unlock()
+	return d.stats.totalFailed()
+}
+
 // logRedirect logs redirects of the http client.
 func logRedirect(req *http.Request, via []*http.Request) error {
 	vs := make([]string, len(via))
@@ -130,7 +233,7 @@ func (d *downloader) httpClient() util.ClientWithContext {
 
 	hClient.Transport = &http.Transport{
 		TLSClientConfig: &tlsConfig,
-		Proxy:           http.ProxyFromEnvironment,
+		Proxy:           d.cfg.proxies.Proxy,
 	}
 
 	client := util.Client(&hClient)
@@ -148,6 +251,14 @@ func (d *downloader) httpClient() util.ClientWithContext {
 		Header: d.cfg.ExtraHeader,
 	}
 
+	// Count the requests.
+	if d.metrics != nil {
+		cwc = &metrics.CountingClient{
+			Client:   cwc,
+			Requests: d.metrics.requests,
+		}
+	}
+
 	// Add optional URL logging.
 	if d.cfg.verbose() {
 		cwc = &util.LoggingClient{
@@ -164,9 +275,41 @@ func (d *downloader) httpClient() util.ClientWithContext {
 		}
 	}
 
+	// Add optional per host limits.
+	if d.cfg.hostLimiter != nil {
+		cwc = &util.HostLimitingClient{
+			Client:  cwc,
+			Limiter: d.cfg.hostLimiter,
+		}
+	}
+
+	// Retry transient failures.
+	if retries := d.cfg.retries(); retries > 0 {
+		cwc = &util.RetryingClient{
+			Client:   cwc,
+			Retries:  retries,
+			Delay:    d.cfg.RetryDelay,
+			MaxDelay: d.cfg.RetryMaxDelay,
+			Log:      retryLog("downloader"),
+		}
+	}
+
 	return cwc
 }
 
+// retryLog logs the retries of a [util.RetryingClient].
+func retryLog(who string) func(string, string, int, time.Duration, string) {
+	return func(method, url string, attempt int, wait time.Duration, reason string) {
+		slog.Warn("Retrying request",
+			"who", who,
+			"method", method,
+			"url", url,
+			"attempt", attempt,
+			"wait", wait,
+			"reason", reason)
+	}
+}
+
 // httpLog does structured logging in a [util.LoggingClient].
 func httpLog(who string) func(string, string) {
 	return func(method, url string) {
@@ -207,12 +350,18 @@ func (d *downloader) enumerate(ctx context.Context, domain string) error {
 	return nil
 }
 
-func (d *downloader) download(ctx context.Context, domain string) error {
+func (d *downloader) download(ctx context.Context, domain string) (err error) {
+	dr := d.report.domain(domain)
+	defer func() { dr.finish(err) }()
+
+	// Only prune based on a current listing.
+	delete(d.listings, domain)
+
 	client := d.httpClient()
 
 	loader := csaf.NewProviderMetadataLoader(client)
 
-	lpmd := loader.LoadWithContext(ctx, domain)
+	lpmd := d.loadProviderMetadata(ctx, loader, domain)
 
 	if !lpmd.Valid() {
 		for i := range lpmd.Messages {
@@ -233,12 +382,15 @@ func (d *downloader) download(ctx context.Context, domain string) error {
 	if err != nil {
 		return fmt.Errorf("invalid URL '%s': %v", lpmd.URL, err)
 	}
+	d.pmdURLs[domain] = lpmd.URL
+	dr.providerMetadata(lpmd.URL)
 
 	expr := util.NewPathEval()
 
 	if err := d.loadOpenPGPKeys(
 		ctx,
 		client,
+		pmdURL.Hostname(),
 		lpmd.Document,
 		expr,
 	); err != nil {
@@ -260,13 +412,51 @@ func (d *downloader) download(ctx context.Context, domain string) error {
 
 	afp.StreamingROLIEParser = d.cfg.StreamingROLIEParser
 
-	return afp.ProcessWithContext(ctx, func(label csaf.TLPLabel, files []csaf.AdvisoryFile) error {
-		return d.downloadFiles(ctx, label, files)
-	})
+	started := time.Now()
+
+	// Only look at the changes since the last synchronization.
+	if d.state != nil && !d.cfg.FullSync {
+		last, err := d.state.lastSync(lpmd.URL)
+		if err != nil {
+			return fmt.Errorf("loading synchronization state failed: %w", err)
+		}
+		if !last.IsZero() {
+			since := last.Add(-syncDeltaOverlap)
+			slog.Info("Only downloading advisories changed since last synchronization",
+				"domain", domain,
+				"since", since)
+			ageAccept := afp.AgeAccept
+			afp.AgeAccept = func(t time.Time) bool {
+				return !t.Before(since) && (ageAccept == nil || ageAccept(t))
+			}
+		}
+	}
+
+	failed := d.totalFailed()
+
+	if err := afp.ProcessWithContext(ctx, func(label csaf.TLPLabel, files []csaf.AdvisoryFile) error {
+		return d.downloadFiles(ctx, dr, pmdURL.Hostname(), label, files)
+	}); err != nil {
+		return err
+	}
+
+	if ctx.Err() == nil {
+		d.updateListing(ctx, domain, client, lpmd.Document, pmdURL)
+	}
+
+	// Only remember complete synchronizations.
+	if d.state != nil && ctx.Err() == nil && d.totalFailed() == failed {
+		if err := d.state.setLastSync(lpmd.URL, started); err != nil {
+			return fmt.Errorf("storing synchronization state failed: %w", err)
+		}
+	}
+	return nil
 }
 
 func (d *downloader) downloadFiles(
 	ctx context.Context,
+	dr *domainReport,
+	domain string,
 	label csaf.TLPLabel,
 	files []csaf.AdvisoryFile,
 ) error {
@@ -291,17 +481,19 @@ func (d *downloader) downloadFiles(
 
 	for range n {
 		wg.Add(1)
-		go d.downloadWorker(ctx, &wg, label, advisoryCh, errorCh, pool)
+		go d.downloadWorker(ctx, &wg, dr, domain, label, advisoryCh, errorCh, pool)
 	}
 
 allFiles:
-	for _, file := range files {
+	for i, file := range files {
+		d.metrics.queued("advisories", len(files)-i)
 		select {
 		case advisoryCh <- file:
 		case <-ctx.Done():
 			break allFiles
 		}
 	}
+	d.metrics.queued("advisories", 0)
 
 	close(advisoryCh)
 	wg.Wait()
@@ -314,23 +506,19 @@ allFiles:
 func (d *downloader) loadOpenPGPKeys(
 	ctx context.Context,
 	client util.ClientWithContext,
+	domain string,
 	doc any,
 	expr *util.PathEval,
 ) error {
-	src, err := expr.Eval("$.public_openpgp_keys", doc)
-	if err != nil {
-		// no keys.
-		return nil
-	}
-
 	var keys []csaf.PGPKey
-	if err := util.ReMarshalJSON(&keys, src); err != nil {
-		return err
+	if src, err := expr.Eval("$.public_openpgp_keys", doc); err == nil {
+		if err := util.ReMarshalJSON(&keys, src); err != nil {
+			return err
+		}
 	}
 
-	if len(keys) == 0 {
-		return nil
-	}
+	// Keys to be checked against the trust store.
+	var loaded []*crypto.Key
 
 	// Try to load
 
@@ -383,6 +571,10 @@ func (d *downloader) loadOpenPGPKeys(
 				"url", u, "fingerprint", key.Fingerprint, "remote-fingerprint", ckey.GetFingerprint())
 			continue
 		}
+		if d.trust != nil {
+			loaded = append(loaded, ckey)
+			continue
+		}
 		if d.keys == nil {
 			if keyring, err := crypto.NewKeyRing(ckey); err != nil {
 				slog.Warn(
@@ -396,6 +588,15 @@ func (d *downloader) loadOpenPGPKeys(
 			d.keys.AddKey(ckey)
 		}
 	}
+
+	if d.trust != nil {
+		// Only the keys of this provider allowed by the trust store are used.
+		keyring, err := d.trust.KeyRing(domain, loaded)
+		if err != nil {
+			return fmt.Errorf("checking OpenPGP keys against trust store failed: %w", err)
+		}
+		d.keys = keyring
+	}
 	return nil
 }
 
@@ -432,10 +633,15 @@ type downloadContext struct {
 	lower              string
 	stats              stats
 	expr               *util.PathEval
+	report             *domainReport
+	// domain is the host of the provider-metadata.json.
+	domain string
 }
 
 func newDownloadContext(
 	d *downloader,
+	dr *domainReport,
+	domain string,
 	label csaf.TLPLabel,
 	pool misc.BufferPool,
 ) *downloadContext {
@@ -445,6 +651,8 @@ func newDownloadContext(
 		pool:   pool,
 		lower:  strings.ToLower(string(label)),
 		expr:   util.NewPathEval(),
+		report: dr,
+		domain: domain,
 	}
 	dc.dateExtract = util.TimeMatcher(&dc.initialReleaseDate, time.RFC3339)
 	return dc
@@ -455,9 +663,12 @@ func (dc *downloadContext) downloadAdvisory(
 	file csaf.AdvisoryFile,
 	errorCh chan<- error,
 ) error {
+	ar := dc.report.advisory(file.URL())
+
 	u, err := url.Parse(file.URL())
 	if err != nil {
 		dc.stats.downloadFailed++
+		ar.failed("url", err)
 		slog.Warn("Ignoring invalid URL",
 			"url", file.URL(),
 			"error", err)
@@ -466,6 +677,7 @@ func (dc *downloadContext) downloadAdvisory(
 
 	if dc.d.cfg.ignoreURL(file.URL()) {
 		slog.Debug("Ignoring URL", "url", file.URL())
+		ar.outcome(outcomeIgnored, "ignore_pattern")
 		return nil
 	}
 
@@ -473,14 +685,37 @@ func (dc *downloadContext) downloadAdvisory(
 	filename := filepath.Base(u.Path)
 	if !util.ConformingFileName(filename) {
 		dc.stats.filenameFailed++
+		ar.failed("filename", fmt.Errorf("filename %q not conforming", filename))
 		slog.Warn("Ignoring none conforming filename",
 			"filename", filename)
 		return nil
 	}
 
-	resp, err := dc.client.GetWithContext(ctx, file.URL())
+	prev := dc.previous(file)
+	if prev != nil && !prev.hasValidators() && dc.unchangedHash(ctx, file, prev) {
+		dc.stats.notModified++
+		ar.outcome(outcomeNotModified, "")
+		slog.Debug("Advisory not modified", "url", file.URL())
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, file.URL(), nil)
 	if err != nil {
 		dc.stats.downloadFailed++
+		ar.failed("download", err)
+		slog.Warn("Cannot create request",
+			"url", file.URL(),
+			"error", err)
+		return nil
+	}
+	if prev != nil {
+		prev.conditional(req)
+	}
+
+	resp, err := dc.client.Do(req)
+	if err != nil {
+		dc.stats.downloadFailed++
+		ar.failed("download", err)
 		slog.Warn("Cannot GET",
 			"url", file.URL(),
 			"error", err)
@@ -488,8 +723,16 @@ func (dc *downloadContext) downloadAdvisory(
 	}
 	defer resp.Body.Close()
 
+	if prev != nil && resp.StatusCode == http.StatusNotModified {
+		dc.stats.notModified++
+		ar.outcome(outcomeNotModified, "")
+		slog.Debug("Advisory not modified", "url", file.URL())
+		return nil
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		dc.stats.downloadFailed++
+		ar.failed("download", fmt.Errorf("status %s", resp.Status))
 		slog.Warn("Cannot load",
 			"url", file.URL(),
 			"status", resp.Status,
@@ -563,15 +806,20 @@ func (dc *downloadContext) downloadAdvisory(
 
 	if err := misc.StrictJSONParse(tee, &doc); err != nil {
 		dc.stats.downloadFailed++
+		ar.failed("json", err)
 		slog.Warn("Downloading failed",
 			"url", file.URL(),
 			"error", err)
 		return nil
 	}
 
+	// Log the tracking ID of the advisory along with its URL.
+	var id string
+	dc.expr.Extract(`$.document.tracking.id`, util.StringMatcher(&id), false, doc)
+	log := slog.With("url", file.URL(), "advisory", id)
+
 	if !utf8.Valid(data.Bytes()) {
-		slog.Warn("Invalid UTF-8 in file",
-			"url", file.URL())
+		log.Warn("Invalid UTF-8 in file")
 	}
 
 	// Compare the checksums.
@@ -655,38 +903,60 @@ func (dc *downloadContext) downloadAdvisory(
 
 	// Run all the validations.
 	valStatus := notValidatedValidationStatus
-	for _, check := range []func() error{
-		s256Check,
-		s512Check,
-		keysCheck,
-		schemaCheck,
-		filenameCheck,
-		remoteValidatorCheck,
+	for _, check := range []struct {
+		name  string
+		check func() error
+	}{
+		{"sha256", s256Check},
+		{"sha512", s512Check},
+		{"signature", keysCheck},
+		{"schema", schemaCheck},
+		{"filename", filenameCheck},
+		{"remote_validator", remoteValidatorCheck},
 	} {
-		if err := check(); err != nil {
-			slog.Error("Validation check failed", "error", err)
+		if err := check.check(); err != nil {
+			log.Error("Validation check failed", "check", check.name, "error", err)
+			ar.failed(check.name, err)
 			valStatus.update(invalidValidationStatus)
 			if dc.d.cfg.ValidationMode == validationStrict {
+				ar.validated(valStatus)
 				return nil
 			}
 		}
 	}
 	valStatus.update(validValidationStatus)
+	ar.validated(valStatus)
+
+	// Drop advisories not matching the content filters.
+	if reason := dc.d.cfg.contentFilter.reject(dc.expr, doc); reason != "" {
+		dc.stats.filtered++
+		ar.outcome(outcomeFiltered, reason)
+		log.Info("Advisory filtered out", "reason", reason)
+		// Remember it to not fetch it again as long as it is unchanged.
+		if valStatus == validValidationStatus {
+			dc.recordSync(file, resp, data.Bytes(), doc, "")
+		}
+		return nil
+	}
 
 	// Send to forwarder
 	if dc.d.forwarder != nil {
-		dc.d.forwarder.forward(
-			ctx,
-			filename, data.String(),
-			valStatus,
-			string(s256Data),
-			string(s512Data))
+		dc.d.forwarder.forwardAdvisory(ctx, &forwardedAdvisory{
+			Filename: filename,
+			Doc:      data.String(),
+			Status:   valStatus,
+			SHA256:   string(s256Data),
+			SHA512:   string(s512Data),
+			report:   ar,
+		})
 	}
 
 	if dc.d.cfg.NoStore {
 		// Do not write locally.
 		if valStatus == validValidationStatus {
 			dc.stats.succeeded++
+			ar.outcome(outcomeDownloaded, "")
+			dc.recordSync(file, resp, data.Bytes(), doc, "")
 		}
 		return nil
 	}
@@ -694,8 +964,7 @@ func (dc *downloadContext) downloadAdvisory(
 	if err := dc.expr.Extract(
 		`$.document.tracking.initial_release_date`, dc.dateExtract, false, doc,
 	); err != nil {
-		slog.Warn("Cannot extract initial_release_date from advisory",
-			"url", file.URL())
+		log.Warn("Cannot extract initial_release_date from advisory")
 		dc.initialReleaseDate = time.Now()
 	}
 	dc.initialReleaseDate = dc.initialReleaseDate.UTC()
@@ -708,15 +977,25 @@ func (dc *downloadContext) downloadAdvisory(
 		newDir = dc.d.cfg.Directory
 	}
 
-	// Do we have a configured destination folder?
-	if dc.d.cfg.Folder != "" {
+	// Do we have a configured destination folder or layout?
+	switch {
+	case dc.d.cfg.Folder != "":
 		newDir = path.Join(newDir, dc.d.cfg.Folder)
-	} else {
+	case dc.d.cfg.layout != nil:
+		dir, err := dc.layoutDir(doc)
+		if err != nil {
+			ar.failed("store", err)
+			errorCh <- err
+			return nil
+		}
+		newDir = path.Join(newDir, dir)
+	default:
 		newDir = path.Join(newDir, dc.lower, strconv.Itoa(dc.initialReleaseDate.Year()))
 	}
 
 	if newDir != dc.lastDir {
 		if err := dc.d.mkdirAll(newDir, 0755); err != nil {
+			ar.failed("store", err)
 			errorCh <- err
 			return nil
 		}
@@ -726,6 +1005,15 @@ func (dc *downloadContext) downloadAdvisory(
 	// Write advisory to file
 	path := filepath.Join(dc.lastDir, filename)
 
+	// Keep the revision which is replaced.
+	if dc.d.cfg.ArchiveRevisions {
+		if err := dc.archiveRevision(path, data.Bytes(), doc); err != nil {
+			ar.failed("store", err)
+			errorCh <- fmt.Errorf("archiving revision of %q failed: %w", path, err)
+			return nil
+		}
+	}
+
 	// Write data to disk.
 	for _, x := range []struct {
 		p string
@@ -738,6 +1026,7 @@ func (dc *downloadContext) downloadAdvisory(
 	} {
 		if x.d != nil {
 			if err := os.WriteFile(x.p, x.d, 0644); err != nil {
+				ar.failed("store", err)
 				errorCh <- err
 				return nil
 			}
@@ -745,13 +1034,22 @@ func (dc *downloadContext) downloadAdvisory(
 	}
 
 	dc.stats.succeeded++
-	slog.Info("Written advisory", "path", path)
+	ar.stored(path)
+	log.Info("Written advisory", "path", path)
+	// Advisories which failed validation are fetched again
+	// in the next run as the upstream copy may get fixed.
+	if valStatus == validValidationStatus {
+		dc.recordSync(file, resp, data.Bytes(), doc, path)
+		dc.indexAdvisory(file, data.Bytes(), doc, path)
+	}
 	return nil
 }
 
 func (d *downloader) downloadWorker(
 	ctx context.Context,
 	wg *sync.WaitGroup,
+	dr *domainReport,
+	domain string,
 	label csaf.TLPLabel,
 	files <-chan csaf.AdvisoryFile,
 	errorCh chan<- error,
@@ -759,7 +1057,7 @@ func (d *downloader) downloadWorker(
 ) {
 	defer wg.Done()
 
-	dc := newDownloadContext(d, label, pool)
+	dc := newDownloadContext(d, dr, domain, label, pool)
 
 	// Add collected stats back to total.
 	defer d.addStats(&dc.stats)
@@ -775,7 +1073,14 @@ func (d *downloader) downloadWorker(
 		case <-ctx.Done():
 			return
 		}
-		if err := dc.downloadAdvisory(ctx, file, errorCh); err != nil {
+		// Give the current advisory some time to finish if we are
+		// cancelled to not leave partially written files behind.
+		before := dc.stats
+		graceCtx, cancel := withGrace(ctx, downloadGrace)
+		err := dc.downloadAdvisory(graceCtx, file, errorCh)
+		cancel()
+		d.metrics.count(&before, &dc.stats)
+		if err != nil {
 			slog.Error("download terminated", "error", err)
 			return
 		}
@@ -836,7 +1141,7 @@ func loadHashes(ctx context.Context, client util.ClientWithContext, hashes []has
 					"url", h.url,
 					"error", err)
 			} else {
-				slog.Info("Hash not present", "hash", h.hashType, "file", h.url)
+				slog.Info("Hash not present", "hash", h.hashType, "url", h.url)
 			}
 		} else {
 			switch h.hashType {
@@ -881,17 +1186,28 @@ func loadHash(ctx context.Context, client util.ClientWithContext, p string) ([]b
 // run performs the downloads for all the given domains.
 func (d *downloader) run(ctx context.Context, domains []string) error {
 	defer d.stats.log()
+	domains = d.addAggregatorDomains(ctx, domains)
+	if len(domains) == 0 {
+		// Do not prune everything if the aggregators
+		// could not be loaded or list nothing matching.
+		slog.Warn("No domains to download from")
+		return nil
+	}
 	for _, domain := range domains {
 		if err := d.download(ctx, domain); err != nil {
 			return err
 		}
 	}
-	return nil
+	if err := d.prune(domains); err != nil {
+		return err
+	}
+	return d.writeProviderTree()
 }
 
 // runEnumerate performs the enumeration of PMDs for all the given domains.
 func (d *downloader) runEnumerate(ctx context.Context, domains []string) error {
 	defer d.stats.log()
+	domains = d.addAggregatorDomains(ctx, domains)
 	for _, domain := range domains {
 		if err := d.enumerate(ctx, domain); err != nil {
 			return err
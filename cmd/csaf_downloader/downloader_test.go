@@ -10,11 +10,15 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"log/slog"
+	"net/http"
 	"net/http/httptest"
 	"os"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gocsaf/csaf/v3/internal/options"
 	"github.com/gocsaf/csaf/v3/internal/testutil"
@@ -158,3 +162,76 @@ func TestShaMarking(t *testing.T) {
 		})
 	}
 }
+
+func TestWithGrace(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	graceCtx, stop := withGrace(ctx, 50*time.Millisecond)
+	defer stop()
+
+	cancel()
+	if graceCtx.Err() != nil {
+		t.Fatal("context cancelled before grace period")
+	}
+	select {
+	case <-graceCtx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("context not cancelled after grace period")
+	}
+
+	graceCtx, stop = withGrace(context.Background(), time.Hour)
+	stop()
+	if graceCtx.Err() == nil {
+		t.Fatal("context not cancelled when stopped")
+	}
+}
+
+func TestForwardDownloaded(t *testing.T) {
+	params := testutil.ProviderParams{
+		EnableSha256: true,
+		EnableSha512: true,
+	}
+	provider := httptest.NewTLSServer(testutil.ProviderHandler(&params, false))
+	defer provider.Close()
+	params.URL = provider.URL
+
+	var (
+		mu        sync.Mutex
+		forwarded []string
+	)
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var doc struct {
+			Filename string `json:"filename"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&doc); err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		forwarded = append(forwarded, doc.Filename)
+		mu.Unlock()
+	}))
+	defer target.Close()
+
+	cfg := config{
+		LogLevel:      &options.LogLevel{Level: slog.LevelError},
+		Directory:     t.TempDir(),
+		ForwardTarget: forwardJSON,
+		ForwardURL:    target.URL,
+	}
+	if err := cfg.prepare(); err != nil {
+		t.Fatal(err)
+	}
+	client := util.Client(provider.Client())
+	if err := withDownloader(&cfg, nil, func(d *downloader) error {
+		d.client = &client
+		return d.run(context.Background(),
+			[]string{provider.URL + "/provider-metadata.json"})
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(forwarded) != 1 || forwarded[0] != "avendor-advisory-0004.json" {
+		t.Errorf("unexpected forwarded advisories %v", forwarded)
+	}
+}
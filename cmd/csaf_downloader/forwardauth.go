@@ -0,0 +1,161 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenExpiryMargin is the time before the expiry of
+// a token when it is already refreshed.
+const tokenExpiryMargin = 30 * time.Second
+
+// tokenProvider fetches bearer tokens from an OAuth2 token
+// endpoint with the client credentials grant (RFC 6749, section 4.4)
+// and refreshes them when they expire.
+type tokenProvider struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+	client       *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// tokenTransport adds bearer tokens to the requests.
+type tokenTransport struct {
+	base   http.RoundTripper
+	tokens *tokenProvider
+}
+
+// get returns a valid token. A new one is fetched if there is none
+// or the current one is about to expire.
+func (tp *tokenProvider) get(ctx context.Context) (string, error) {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	if tp.token != "" &&
+		(tp.expires.IsZero() || time.Now().Add(tokenExpiryMargin).Before(tp.expires)) {
+		return tp.token, nil
+	}
+	token, expires, err := tp.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	tp.token, tp.expires = token, expires
+	return token, nil
+}
+
+// invalidate drops the current token.
+func (tp *tokenProvider) invalidate() {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	tp.token, tp.expires = "", time.Time{}
+}
+
+// fetch requests a new token from the token endpoint.
+func (tp *tokenProvider) fetch(ctx context.Context) (string, time.Time, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(tp.scopes) > 0 {
+		form.Set("scope", strings.Join(tp.scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, tp.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(tp.clientID), url.QueryEscape(tp.clientSecret))
+
+	requested := time.Now()
+	res, err := tp.client.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("fetching token failed: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		msg, _ := limitedString(res.Body, 512)
+		return "", time.Time{}, fmt.Errorf(
+			"fetching token failed: status code %d: %s", res.StatusCode, msg)
+	}
+	var result struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return "", time.Time{}, fmt.Errorf("decoding token failed: %w", err)
+	}
+	if result.AccessToken == "" {
+		return "", time.Time{}, errors.New("token endpoint returned no access token")
+	}
+	if !strings.EqualFold(result.TokenType, "bearer") {
+		return "", time.Time{}, fmt.Errorf("unsupported token type %q", result.TokenType)
+	}
+	var expires time.Time
+	if result.ExpiresIn > 0 {
+		expires = requested.Add(time.Duration(result.ExpiresIn) * time.Second)
+	}
+	return result.AccessToken, expires, nil
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (tt *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := tt.tokens.get(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	// A RoundTripper must not modify the given request.
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	res, err := tt.base.RoundTrip(req)
+	if err == nil && res.StatusCode == http.StatusUnauthorized {
+		// The token may have been revoked. Fetch a new one next time.
+		tt.tokens.invalidate()
+	}
+	return res, err
+}
+
+// forwardTransport returns the transport used to forward
+// the advisories to the configured HTTP endpoint.
+func (f *forwarder) forwardTransport() http.RoundTripper {
+	tlsConfig := tls.Config{
+		InsecureSkipVerify: f.cfg.ForwardInsecure,
+		Certificates:       f.cfg.forwardCerts,
+	}
+	var transport http.RoundTripper = &http.Transport{
+		TLSClientConfig: &tlsConfig,
+		Proxy:           http.ProxyFromEnvironment,
+	}
+	if f.cfg.ForwardTokenURL != "" {
+		transport = &tokenTransport{
+			base: transport,
+			tokens: &tokenProvider{
+				tokenURL:     f.cfg.ForwardTokenURL,
+				clientID:     f.cfg.ForwardClientID,
+				clientSecret: f.cfg.ForwardClientSecret,
+				scopes:       f.cfg.ForwardScopes,
+				client:       &http.Client{Transport: transport},
+			},
+		}
+	}
+	return transport
+}
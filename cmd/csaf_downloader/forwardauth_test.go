@@ -0,0 +1,113 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gocsaf/csaf/v3/internal/options"
+)
+
+func TestForwardBearerToken(t *testing.T) {
+	var issued int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "client" || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err := r.ParseForm(); err != nil ||
+			r.PostForm.Get("grant_type") != "client_credentials" ||
+			r.PostForm.Get("scope") != "upload admin" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		issued++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":3600}`, issued)
+	})
+	var revoked bool
+	mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		want := fmt.Sprintf("Bearer token-%d", issued)
+		if got := r.Header.Get("Authorization"); got != want || revoked {
+			revoked = false
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cfg := &config{
+		LogLevel:            &options.LogLevel{Level: slog.LevelError},
+		ForwardURL:          server.URL + "/upload",
+		ForwardTokenURL:     server.URL + "/token",
+		ForwardClientID:     "client",
+		ForwardClientSecret: "secret",
+		ForwardScopes:       []string{"upload", "admin"},
+	}
+	if err := cfg.prepareForwarding(); err != nil {
+		t.Fatal(err)
+	}
+	fw := newForwarder(cfg)
+	ctx := context.Background()
+
+	for range 2 {
+		if err := fw.send(ctx, testForwarded); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if issued != 1 {
+		t.Errorf("token fetched %d times, want 1", issued)
+	}
+
+	// A rejected token is replaced by a new one.
+	revoked = true
+	if err := fw.send(ctx, testForwarded); err == nil {
+		t.Fatal("expected error with revoked token")
+	}
+	if err := fw.send(ctx, testForwarded); err != nil {
+		t.Fatal(err)
+	}
+	if issued != 2 {
+		t.Errorf("token fetched %d times, want 2", issued)
+	}
+
+	// Missing client ID.
+	cfg.ForwardClientID = ""
+	if err := cfg.prepareForwarding(); err == nil {
+		t.Error("expected error without client ID")
+	}
+}
+
+func TestForwardClientCertificate(t *testing.T) {
+	cert, key := "../../internal/certs/data/testclient.crt", "../../internal/certs/data/testclientkey.pem"
+	cfg := &config{
+		ForwardClientCert: &cert,
+		ForwardClientKey:  &key,
+	}
+	if err := cfg.prepareCertificates(); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.forwardCerts) != 1 || len(cfg.clientCerts) != 0 {
+		t.Fatalf("got %d forward and %d client certificates",
+			len(cfg.forwardCerts), len(cfg.clientCerts))
+	}
+	transport := newForwarder(cfg).forwardTransport().(*http.Transport)
+	if n := len(transport.TLSClientConfig.Certificates); n != 1 {
+		t.Errorf("transport has %d certificates, want 1", n)
+	}
+}
@@ -11,7 +11,6 @@ package main
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
 	"io"
 	"log/slog"
 	"mime/multipart"
@@ -19,6 +18,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/gocsaf/csaf/v3/internal/misc"
 	"github.com/gocsaf/csaf/v3/util"
@@ -50,10 +50,17 @@ func (vs *validationStatus) update(status validationStatus) {
 type forwarder struct {
 	cfg    *config
 	cmds   chan func(*forwarder)
+	done   chan struct{}
 	client util.ClientWithContext
+	target forwardTarget
+	queue  *forwardQueue
+	wakeup time.Time
+
+	metrics *downloaderMetrics
 
 	failed    int
 	succeeded int
+	retried   int
 }
 
 // newForwarder creates a new forwarder.
@@ -62,18 +69,65 @@ func newForwarder(cfg *config) *forwarder {
 	if queue < 1 {
 		queue = 1
 	}
-	return &forwarder{
+	f := &forwarder{
 		cfg:  cfg,
 		cmds: make(chan func(*forwarder), queue),
+		done: make(chan struct{}),
 	}
+	f.target = newForwardTarget(f)
+	return f
 }
 
 // run runs the forwarder. Meant to be used in a Go routine.
 func (f *forwarder) run() {
+	defer close(f.done)
 	defer slog.Debug("forwarder done")
 
-	for cmd := range f.cmds {
-		cmd(f)
+	if f.queue == nil {
+		for cmd := range f.cmds {
+			cmd(f)
+			f.queued()
+		}
+		return
+	}
+
+	defer func() {
+		if err := f.queue.close(); err != nil {
+			slog.Error("Closing forward queue failed", "error", err)
+		}
+	}()
+
+	// Retry the queued advisories when they are due.
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	armed := time.Now()
+	for {
+		select {
+		case cmd, ok := <-f.cmds:
+			if !ok {
+				return
+			}
+			cmd(f)
+		case <-timer.C:
+			armed, f.wakeup = time.Time{}, time.Time{}
+			f.schedule(f.retryDue())
+		}
+		f.queued()
+		if !f.wakeup.IsZero() && (armed.IsZero() || f.wakeup.Before(armed)) {
+			timer.Reset(time.Until(f.wakeup))
+			armed = f.wakeup
+		}
+	}
+}
+
+// queued updates the metrics of the queue depths.
+func (f *forwarder) queued() {
+	if f.metrics == nil {
+		return
+	}
+	f.metrics.queued("forward", len(f.cmds))
+	if f.queue != nil {
+		f.metrics.queued("forward_retry", f.queue.depth())
 	}
 }
 
@@ -82,12 +136,25 @@ func (f *forwarder) close() {
 	close(f.cmds)
 }
 
+// wait waits till the queue of the forwarder is drained
+// after it was closed.
+func (f *forwarder) wait() {
+	<-f.done
+}
+
 // log logs the current statistics.
 func (f *forwarder) log() {
 	f.cmds <- func(f *forwarder) {
-		slog.Info("Forward statistics",
+		args := []any{
 			"succeeded", f.succeeded,
-			"failed", f.failed)
+			"failed", f.failed,
+		}
+		if f.queue != nil {
+			args = append(args,
+				"retried", f.retried,
+				"queued", f.queue.depth())
+		}
+		slog.Info("Forward statistics", args...)
 	}
 }
 
@@ -98,16 +165,8 @@ func (f *forwarder) httpClient() util.ClientWithContext {
 		return f.client
 	}
 
-	hClient := http.Client{}
-
-	var tlsConfig tls.Config
-	if f.cfg.ForwardInsecure {
-		tlsConfig.InsecureSkipVerify = true
-	}
-
-	hClient.Transport = &http.Transport{
-		TLSClientConfig: &tlsConfig,
-		Proxy:           http.ProxyFromEnvironment,
+	hClient := http.Client{
+		Transport: f.forwardTransport(),
 	}
 
 	client := util.Client(&hClient)
@@ -191,8 +250,13 @@ func (f *forwarder) buildRequest(
 }
 
 // storeFailedAdvisory stores an advisory in a special folder
-// in case the forwarding failed.
-func (f *forwarder) storeFailedAdvisory(filename, doc, sha256, sha512 string) error {
+// in case the forwarding failed. The validation status is kept
+// in a ".status" file next to it.
+func (f *forwarder) storeFailedAdvisory(
+	filename, doc string,
+	status validationStatus,
+	sha256, sha512 string,
+) error {
 	// Create special folder if it does not exist.
 	dir := filepath.Join(f.cfg.Directory, failedForwardDir)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -204,6 +268,7 @@ func (f *forwarder) storeFailedAdvisory(filename, doc, sha256, sha512 string) er
 		d string
 	}{
 		{filename, doc},
+		{filename + ".status", string(status)},
 		{filename + ".sha256", sha256},
 		{filename + ".sha512", sha512},
 	} {
@@ -218,9 +283,13 @@ func (f *forwarder) storeFailedAdvisory(filename, doc, sha256, sha512 string) er
 }
 
 // storeFailed is a logging wrapper around storeFailedAdvisory.
-func (f *forwarder) storeFailed(filename, doc, sha256, sha512 string) {
+func (f *forwarder) storeFailed(
+	filename, doc string,
+	status validationStatus,
+	sha256, sha512 string,
+) {
 	f.failed++
-	if err := f.storeFailedAdvisory(filename, doc, sha256, sha512); err != nil {
+	if err := f.storeFailedAdvisory(filename, doc, status, sha256, sha512); err != nil {
 		slog.Error("Storing advisory failed forwarding failed",
 			"error", err)
 	}
@@ -239,48 +308,45 @@ func limitedString(r io.Reader, maxLength int) (string, error) {
 	return msg.String(), nil
 }
 
-// forward sends a given document with filename, status and
-// checksums to the forwarder. This is async to the degree
-// till the configured queue size is filled.
-func (f *forwarder) forward(
-	ctx context.Context,
-	filename, doc string,
-	status validationStatus,
-	sha256, sha512 string,
-) {
+// forwardAdvisory sends a given advisory to the forwarder.
+// This is async to the degree till the configured queue size is filled.
+func (f *forwarder) forwardAdvisory(ctx context.Context, adv *forwardedAdvisory) {
+	// The advisory is sent after its download has finished
+	// and the context of the download is cancelled.
+	ctx = context.WithoutCancel(ctx)
+	adv.report.forwarded(forwardPending)
 	// Run this in the main loop of the forwarder.
+	defer func() { f.metrics.queued("forward", len(f.cmds)) }()
 	f.cmds <- func(f *forwarder) {
-		req, err := f.buildRequest(ctx, filename, doc, status, sha256, sha512)
-		if err != nil {
-			slog.Error("building forward Request failed",
-				"error", err)
-			f.storeFailed(filename, doc, sha256, sha512)
+		if f.queue != nil {
+			now := time.Now().UTC()
+			f.enqueue(&queuedForward{
+				forwardedAdvisory: *adv,
+				Created:           now,
+				Next:              now,
+			})
 			return
 		}
-		res, err := f.httpClient().Do(req)
-		if err != nil {
-			slog.Error("sending forward request failed",
-				"error", err)
-			f.storeFailed(filename, doc, sha256, sha512)
-			return
-		}
-		if res.StatusCode != http.StatusCreated {
-			defer res.Body.Close()
-			if msg, err := limitedString(res.Body, 512); err != nil {
-				slog.Error("reading forward result failed",
-					"error", err)
-			} else {
-				slog.Error("forwarding failed",
-					"filename", filename,
-					"body", msg,
-					"status_code", res.StatusCode)
-			}
-			f.storeFailed(filename, doc, sha256, sha512)
+		if err := f.send(ctx, adv); err != nil {
+			adv.report.forwarded(forwardFailed)
+			f.storeFailed(adv.Filename, adv.Doc, adv.Status, adv.SHA256, adv.SHA512)
 		} else {
+			adv.report.forwarded(forwardSucceeded)
 			f.succeeded++
-			slog.Debug(
-				"forwarding succeeded",
-				"filename", filename)
 		}
 	}
 }
+
+// send delivers the given advisory to the configured target.
+func (f *forwarder) send(ctx context.Context, adv *forwardedAdvisory) error {
+	if err := f.target.deliver(ctx, adv); err != nil {
+		slog.Error("forwarding failed",
+			"filename", adv.Filename,
+			"error", err)
+		return err
+	}
+	slog.Debug(
+		"forwarding succeeded",
+		"filename", adv.Filename)
+	return nil
+}
@@ -259,7 +259,7 @@ func TestStoreFailedAdvisory(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := fw.storeFailedAdvisory("advisory.json", "{}", "256", "512"); err == nil {
+	if err := fw.storeFailedAdvisory("advisory.json", "{}", validValidationStatus, "256", "512"); err == nil {
 		t.Fatal("if the destination exists as a file an error should occur")
 	}
 
@@ -267,7 +267,7 @@ func TestStoreFailedAdvisory(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := fw.storeFailedAdvisory("advisory.json", "{}", "256", "512"); err != nil {
+	if err := fw.storeFailedAdvisory("advisory.json", "{}", validValidationStatus, "256", "512"); err != nil {
 		t.Fatal(err)
 	}
 
@@ -278,7 +278,7 @@ func TestStoreFailedAdvisory(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := fw.storeFailedAdvisory("advisory.json", "{}", "256", "512"); err == nil {
+	if err := fw.storeFailedAdvisory("advisory.json", "{}", validValidationStatus, "256", "512"); err == nil {
 		t.Fatal("expected to fail with an error")
 	}
 
@@ -308,7 +308,7 @@ func TestStoredFailed(t *testing.T) {
 	fw := newForwarder(cfg)
 
 	// An empty filename should lead to an error.
-	fw.storeFailed("", "{}", "256", "512")
+	fw.storeFailed("", "{}", validValidationStatus, "256", "512")
 
 	if fw.failed != 1 {
 		t.Fatalf("got %d expected 1", fw.failed)
@@ -352,6 +352,7 @@ func (fc *fakeClient) Do(*http.Request) (*http.Response, error) {
 		return &http.Response{
 			Status:     http.StatusText(http.StatusCreated),
 			StatusCode: http.StatusCreated,
+			Body:       http.NoBody,
 		}, nil
 	case 1:
 		fc.state = 2
@@ -406,12 +407,13 @@ func TestForwarderForward(t *testing.T) {
 
 	// Iterate through states of http client.
 	for i := 0; i <= 3; i++ {
-		fw.forward(
-			context.Background(),
-			"test.json", "{}",
-			invalidValidationStatus,
-			"256",
-			"512")
+		fw.forwardAdvisory(context.Background(), &forwardedAdvisory{
+			Filename: "test.json",
+			Doc:      "{}",
+			Status:   invalidValidationStatus,
+			SHA256:   "256",
+			SHA512:   "512",
+		})
 	}
 
 	// Make buildRequest fail.
@@ -421,12 +423,13 @@ func TestForwarderForward(t *testing.T) {
 		close(wait)
 	}
 	<-wait
-	fw.forward(
-		context.Background(),
-		"test.json", "{}",
-		invalidValidationStatus,
-		"256",
-		"512")
+	fw.forwardAdvisory(context.Background(), &forwardedAdvisory{
+		Filename: "test.json",
+		Doc:      "{}",
+		Status:   invalidValidationStatus,
+		SHA256:   "256",
+		SHA512:   "512",
+	})
 
 	fw.close()
 
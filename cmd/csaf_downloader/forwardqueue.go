@@ -0,0 +1,341 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var forwardQueueBucket = []byte("forward_queue")
+
+// queuedForward is an advisory waiting to be forwarded.
+type queuedForward struct {
+	forwardedAdvisory
+	Created   time.Time `json:"created"`
+	Attempts  int       `json:"attempts"`
+	Next      time.Time `json:"next"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// forwardQueue is a persistent queue of advisories
+// to be forwarded.
+type forwardQueue struct {
+	db *bolt.DB
+}
+
+// openForwardQueue opens the queue stored in the given file.
+func openForwardQueue(fname string) (*forwardQueue, error) {
+	db, err := bolt.Open(fname, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(forwardQueueBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &forwardQueue{db: db}, nil
+}
+
+// close closes the underlying database.
+func (fq *forwardQueue) close() error {
+	return fq.db.Close()
+}
+
+// push appends an entry to the queue and returns its key.
+func (fq *forwardQueue) push(qf *queuedForward) (uint64, error) {
+	data, err := json.Marshal(qf)
+	if err != nil {
+		return 0, err
+	}
+	var id uint64
+	err = fq.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(forwardQueueBucket)
+		if id, err = b.NextSequence(); err != nil {
+			return err
+		}
+		return b.Put(queueKey(id), data)
+	})
+	return id, err
+}
+
+// update stores the modified entry with the given key.
+func (fq *forwardQueue) update(id uint64, qf *queuedForward) error {
+	data, err := json.Marshal(qf)
+	if err != nil {
+		return err
+	}
+	return fq.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(forwardQueueBucket).Put(queueKey(id), data)
+	})
+}
+
+// remove removes the entry with the given key.
+func (fq *forwardQueue) remove(id uint64) error {
+	return fq.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(forwardQueueBucket).Delete(queueKey(id))
+	})
+}
+
+// depth returns the number of queued entries.
+func (fq *forwardQueue) depth() int {
+	var n int
+	if err := fq.db.View(func(tx *bolt.Tx) error {
+		n = tx.Bucket(forwardQueueBucket).Stats().KeyN
+		return nil
+	}); err != nil {
+		slog.Error("Reading forward queue failed", "error", err)
+	}
+	return n
+}
+
+// forEach calls fn for all entries in the order they were queued.
+func (fq *forwardQueue) forEach(fn func(uint64, *queuedForward)) error {
+	return fq.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(forwardQueueBucket).ForEach(func(k, v []byte) error {
+			qf := new(queuedForward)
+			if err := json.Unmarshal(v, qf); err != nil {
+				return err
+			}
+			fn(binary.BigEndian.Uint64(k), qf)
+			return nil
+		})
+	})
+}
+
+// due calls fn for all entries which are due at the given time
+// in the order they were queued. It returns the time when
+// the next of the remaining entries is due or the zero time
+// if there is none.
+func (fq *forwardQueue) due(now time.Time, fn func(uint64, *queuedForward)) (time.Time, error) {
+	type entry struct {
+		id uint64
+		qf *queuedForward
+	}
+	var entries []entry
+	if err := fq.forEach(func(id uint64, qf *queuedForward) {
+		if !qf.Next.After(now) {
+			entries = append(entries, entry{id, qf})
+		}
+	}); err != nil {
+		return time.Time{}, err
+	}
+	// Run the callbacks outside of the transaction
+	// as they modify the queue.
+	for _, e := range entries {
+		fn(e.id, e.qf)
+	}
+	var next time.Time
+	if err := fq.forEach(func(_ uint64, qf *queuedForward) {
+		if next.IsZero() || qf.Next.Before(next) {
+			next = qf.Next
+		}
+	}); err != nil {
+		return time.Time{}, err
+	}
+	return next, nil
+}
+
+// queueKey returns the database key of the given sequence number.
+func queueKey(id uint64) []byte {
+	var key [8]byte
+	binary.BigEndian.PutUint64(key[:], id)
+	return key[:]
+}
+
+// backoff returns the waiting time after the given number of failed attempts.
+func (f *forwarder) backoff(attempts int) time.Duration {
+	d := f.cfg.ForwardBackoff
+	for i := 1; i < attempts && d < f.cfg.ForwardMaxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, f.cfg.ForwardMaxBackoff)
+}
+
+// schedule makes sure the forwarder wakes up
+// at the given time to retry queued advisories.
+func (f *forwarder) schedule(t time.Time) {
+	if !t.IsZero() && (f.wakeup.IsZero() || t.Before(f.wakeup)) {
+		f.wakeup = t
+	}
+}
+
+// openQueue opens the persistent queue if configured and
+// re-queues the advisories from the failed forward folder.
+func (f *forwarder) openQueue() error {
+	if f.cfg.ForwardQueueFile == "" {
+		return nil
+	}
+	queue, err := openForwardQueue(f.cfg.forwardQueueFile())
+	if err != nil {
+		return err
+	}
+	f.queue = queue
+	if err := f.replayFailed(); err != nil {
+		slog.Error("Re-queuing failed forwards failed", "error", err)
+	}
+	return nil
+}
+
+// parseValidationStatus returns the validation status stored
+// along with a failed advisory. Unknown values are treated
+// as not validated.
+func parseValidationStatus(s string) validationStatus {
+	switch status := validationStatus(strings.TrimSpace(s)); status {
+	case validValidationStatus, invalidValidationStatus:
+		return status
+	default:
+		return notValidatedValidationStatus
+	}
+}
+
+// replayFailed moves the advisories from the failed forward
+// folder to the persistent queue.
+func (f *forwarder) replayFailed() error {
+	dir := filepath.Join(f.cfg.Directory, failedForwardDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	var replayed int
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		doc, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		readOptional := func(p string) string {
+			data, err := os.ReadFile(p)
+			if err != nil {
+				return ""
+			}
+			return string(data)
+		}
+		now := time.Now().UTC()
+		if _, err := f.queue.push(&queuedForward{
+			forwardedAdvisory: forwardedAdvisory{
+				Filename: name,
+				Doc:      string(doc),
+				Status:   parseValidationStatus(readOptional(path + ".status")),
+				SHA256:   readOptional(path + ".sha256"),
+				SHA512:   readOptional(path + ".sha512"),
+			},
+			Created: now,
+			Next:    now,
+		}); err != nil {
+			return err
+		}
+		for _, p := range []string{
+			path, path + ".status", path + ".sha256", path + ".sha512",
+		} {
+			if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+		replayed++
+	}
+	if replayed > 0 {
+		slog.Info("Re-queued failed forwards", "count", replayed)
+	}
+	return nil
+}
+
+// enqueue stores an advisory in the persistent queue
+// and tries to send it immediately.
+func (f *forwarder) enqueue(qf *queuedForward) {
+	id, err := f.queue.push(qf)
+	if err != nil {
+		slog.Error("Queuing advisory for forwarding failed",
+			"filename", qf.Filename,
+			"error", err)
+		qf.report.forwarded(forwardFailed)
+		f.storeFailed(qf.Filename, qf.Doc, qf.Status, qf.SHA256, qf.SHA512)
+		return
+	}
+	f.attempt(id, qf)
+}
+
+// attempt tries to send a queued advisory. On failure
+// it is re-scheduled with exponential backoff till it
+// gets too old. Expired advisories are stored in the
+// failed forward folder.
+func (f *forwarder) attempt(id uint64, qf *queuedForward) {
+	err := f.send(context.Background(), &qf.forwardedAdvisory)
+	if err == nil {
+		qf.report.forwarded(forwardSucceeded)
+		f.succeeded++
+		if err := f.queue.remove(id); err != nil {
+			slog.Error("Removing advisory from forward queue failed",
+				"filename", qf.Filename,
+				"error", err)
+		}
+		return
+	}
+	qf.Attempts++
+	qf.LastError = err.Error()
+	now := time.Now().UTC()
+	if f.cfg.ForwardMaxAge > 0 && now.Sub(qf.Created) >= f.cfg.ForwardMaxAge {
+		slog.Error("Giving up forwarding advisory",
+			"filename", qf.Filename,
+			"attempts", qf.Attempts,
+			"error", err)
+		if err := f.queue.remove(id); err != nil {
+			slog.Error("Removing advisory from forward queue failed",
+				"filename", qf.Filename,
+				"error", err)
+		}
+		qf.report.forwarded(forwardFailed)
+		f.storeFailed(qf.Filename, qf.Doc, qf.Status, qf.SHA256, qf.SHA512)
+		return
+	}
+	qf.report.forwarded(forwardQueued)
+	f.retried++
+	qf.Next = now.Add(f.backoff(qf.Attempts))
+	slog.Warn("Forwarding failed, will retry",
+		"filename", qf.Filename,
+		"attempts", qf.Attempts,
+		"next", qf.Next,
+		"error", err)
+	if err := f.queue.update(id, qf); err != nil {
+		slog.Error("Updating forward queue failed",
+			"filename", qf.Filename,
+			"error", err)
+	}
+	f.schedule(qf.Next)
+}
+
+// retryDue re-sends the queued advisories which are due.
+// It returns the time when the next retry is due.
+func (f *forwarder) retryDue() time.Time {
+	next, err := f.queue.due(time.Now(), f.attempt)
+	if err != nil {
+		slog.Error("Reading forward queue failed", "error", err)
+		return time.Now().Add(f.cfg.ForwardMaxBackoff)
+	}
+	return next
+}
@@ -0,0 +1,152 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gocsaf/csaf/v3/internal/options"
+)
+
+func TestForwardQueue(t *testing.T) {
+	orig := slog.Default()
+	defer slog.SetDefault(orig)
+	slog.SetDefault(slog.New(slog.NewJSONHandler(io.Discard, nil)))
+
+	for _, test := range []struct {
+		name      string
+		failures  int
+		maxAge    time.Duration
+		delivered int
+		failed    int
+	}{
+		{name: "retried", failures: 3, delivered: 2},
+		{name: "expired", failures: 1000, maxAge: 50 * time.Millisecond, failed: 2},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var (
+				mu        sync.Mutex
+				requests  int
+				delivered = map[string]string{}
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				if requests++; requests <= test.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				_, hdr, err := r.FormFile("advisory")
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				delivered[hdr.Filename] = r.FormValue("validation_status")
+				w.WriteHeader(http.StatusCreated)
+			}))
+			defer server.Close()
+
+			dir := t.TempDir()
+			// An advisory of a previous run which failed to be forwarded.
+			failedDir := filepath.Join(dir, failedForwardDir)
+			if err := os.MkdirAll(failedDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(failedDir, "old.json"), []byte("{}"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filepath.Join(failedDir, "old.json.status"), []byte("valid"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg := &config{
+				LogLevel:          &options.LogLevel{Level: slog.LevelError},
+				Directory:         dir,
+				ForwardURL:        server.URL,
+				ForwardQueueFile:  "queue.db",
+				ForwardBackoff:    time.Millisecond,
+				ForwardMaxBackoff: 10 * time.Millisecond,
+				ForwardMaxAge:     test.maxAge,
+			}
+			fw := newForwarder(cfg)
+			if err := fw.openQueue(); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := os.Stat(filepath.Join(failedDir, "old.json")); !os.IsNotExist(err) {
+				t.Fatal("failed advisory not removed after re-queuing")
+			}
+			go fw.run()
+
+			fw.forwardAdvisory(context.Background(), &forwardedAdvisory{
+				Filename: "new.json",
+				Doc:      "{}",
+				Status:   invalidValidationStatus,
+				SHA256:   "256",
+				SHA512:   "512",
+			})
+
+			deadline := time.Now().Add(5 * time.Second)
+			for {
+				var succeeded, failed, queued int
+				done := make(chan struct{})
+				fw.cmds <- func(f *forwarder) {
+					succeeded, failed, queued = f.succeeded, f.failed, f.queue.depth()
+					close(done)
+				}
+				<-done
+				if queued == 0 {
+					if succeeded != test.delivered || failed != test.failed {
+						t.Errorf("succeeded %d, failed %d, want %d/%d",
+							succeeded, failed, test.delivered, test.failed)
+					}
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("queue not drained: %d entries left", queued)
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+			fw.close()
+			fw.wait()
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(delivered) != test.delivered {
+				t.Errorf("delivered %v", delivered)
+			}
+			for name, want := range map[string]validationStatus{
+				"old.json": validValidationStatus,
+				"new.json": invalidValidationStatus,
+			} {
+				if status, ok := delivered[name]; ok && status != string(want) {
+					t.Errorf("%s delivered as %q, want %q", name, status, want)
+				}
+				_, err := os.Stat(filepath.Join(failedDir, name))
+				if stored := err == nil; stored != (test.failed > 0) {
+					t.Errorf("%s stored in failed folder: %t", name, stored)
+				}
+				if test.failed > 0 {
+					data, err := os.ReadFile(filepath.Join(failedDir, name+".status"))
+					if err != nil || string(data) != string(want) {
+						t.Errorf("%s stored with status %q (%v), want %q", name, data, err, want)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,317 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// forwardTargetType is the kind of destination
+// the advisories are forwarded to.
+type forwardTargetType string
+
+const (
+	forwardMultipart = forwardTargetType("multipart")
+	forwardJSON      = forwardTargetType("json")
+	forwardSpool     = forwardTargetType("spool")
+	forwardCommand   = forwardTargetType("command")
+)
+
+// defaultForwardTemplate is the template of the
+// body of the JSON target if none is configured.
+const defaultForwardTemplate = `{
+  "filename": {{ json .Filename }},
+  "validation_status": {{ json .ValidationStatus }},
+  "sha256": {{ json .SHA256 }},
+  "sha512": {{ json .SHA512 }},
+  "advisory": {{ .Advisory }}
+}`
+
+// forwardedAdvisory is an advisory to be forwarded.
+type forwardedAdvisory struct {
+	Filename string           `json:"filename"`
+	Doc      string           `json:"doc"`
+	Status   validationStatus `json:"status"`
+	SHA256   string           `json:"sha256,omitempty"`
+	SHA512   string           `json:"sha512,omitempty"`
+
+	// report is the entry of the advisory in the run report.
+	report *advisoryReport
+}
+
+// forwardTarget delivers advisories to their destination.
+type forwardTarget interface {
+	deliver(ctx context.Context, adv *forwardedAdvisory) error
+}
+
+// multipartTarget posts the advisories as multipart
+// forms to an HTTP endpoint.
+type multipartTarget struct {
+	f *forwarder
+}
+
+// jsonTarget posts the advisories as JSON documents
+// created from a template to an HTTP endpoint.
+type jsonTarget struct {
+	f    *forwarder
+	tmpl *template.Template
+}
+
+// spoolTarget writes the advisories into a directory.
+type spoolTarget struct {
+	dir string
+}
+
+// commandTarget runs a command for every advisory.
+type commandTarget struct {
+	args []string
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (ftt *forwardTargetType) UnmarshalText(text []byte) error {
+	switch t := forwardTargetType(text); t {
+	case forwardMultipart, forwardJSON, forwardSpool, forwardCommand:
+		*ftt = t
+	default:
+		return fmt.Errorf(
+			`invalid value %q (expected "multipart", "json", "spool" or "command")`, t)
+	}
+	return nil
+}
+
+// UnmarshalFlag implements [flags.UnmarshalFlag].
+func (ftt *forwardTargetType) UnmarshalFlag(value string) error {
+	return ftt.UnmarshalText([]byte(value))
+}
+
+// forwarding returns true if forwarding is configured.
+func (cfg *config) forwarding() bool {
+	switch cfg.ForwardTarget {
+	case forwardSpool:
+		return cfg.ForwardSpoolDir != ""
+	case forwardCommand:
+		return len(cfg.ForwardCommand) > 0
+	default:
+		return cfg.ForwardURL != ""
+	}
+}
+
+// prepareForwarding checks the forwarding options
+// and loads the template of the JSON target.
+func (cfg *config) prepareForwarding() error {
+	if cfg.ForwardTokenURL != "" && cfg.ForwardClientID == "" {
+		return errors.New("forward_token_url needs a forward_client_id")
+	}
+	switch cfg.ForwardTarget {
+	case "", forwardMultipart:
+		return nil
+	case forwardJSON:
+		if cfg.ForwardURL == "" {
+			return errors.New("forward target 'json' needs a forward_url")
+		}
+		text := defaultForwardTemplate
+		if cfg.ForwardTemplate != "" {
+			data, err := os.ReadFile(cfg.ForwardTemplate)
+			if err != nil {
+				return fmt.Errorf("loading forward template failed: %w", err)
+			}
+			text = string(data)
+		}
+		tmpl, err := template.New("forward").Funcs(template.FuncMap{
+			"json": func(v any) (string, error) {
+				data, err := json.Marshal(v)
+				return string(data), err
+			},
+		}).Parse(text)
+		if err != nil {
+			return fmt.Errorf("invalid forward template: %w", err)
+		}
+		cfg.forwardTemplate = tmpl
+	case forwardSpool:
+		if cfg.ForwardSpoolDir == "" {
+			return errors.New("forward target 'spool' needs a forward_spool_dir")
+		}
+	case forwardCommand:
+		if len(cfg.ForwardCommand) == 0 {
+			return errors.New("forward target 'command' needs a forward_command")
+		}
+	}
+	return nil
+}
+
+// newForwardTarget creates the configured target of the forwarder.
+func newForwardTarget(f *forwarder) forwardTarget {
+	switch f.cfg.ForwardTarget {
+	case forwardJSON:
+		return &jsonTarget{f: f, tmpl: f.cfg.forwardTemplate}
+	case forwardSpool:
+		return &spoolTarget{dir: f.cfg.ForwardSpoolDir}
+	case forwardCommand:
+		return &commandTarget{args: f.cfg.ForwardCommand}
+	default:
+		return &multipartTarget{f: f}
+	}
+}
+
+// post sends the given request to the HTTP endpoint.
+// Responses with other status codes than the expected one
+// are reported as errors.
+func (f *forwarder) post(req *http.Request, expected func(int) bool) error {
+	res, err := f.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("sending forward request failed: %w", err)
+	}
+	defer res.Body.Close()
+	if !expected(res.StatusCode) {
+		msg, err := limitedString(res.Body, 512)
+		if err != nil {
+			return fmt.Errorf("reading forward result failed: %w", err)
+		}
+		return fmt.Errorf("status code %d: %s", res.StatusCode, msg)
+	}
+	// Drain the body to allow the connection to be reused.
+	io.Copy(io.Discard, res.Body)
+	return nil
+}
+
+// deliver implements [forwardTarget].
+func (mt *multipartTarget) deliver(ctx context.Context, adv *forwardedAdvisory) error {
+	req, err := mt.f.buildRequest(
+		ctx, adv.Filename, adv.Doc, adv.Status, adv.SHA256, adv.SHA512)
+	if err != nil {
+		return fmt.Errorf("building forward request failed: %w", err)
+	}
+	return mt.f.post(req, func(code int) bool { return code == http.StatusCreated })
+}
+
+// hashValue returns the hex digest of the content of a hash file.
+func hashValue(content string) string {
+	if fields := strings.Fields(content); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// deliver implements [forwardTarget].
+func (jt *jsonTarget) deliver(ctx context.Context, adv *forwardedAdvisory) error {
+	var body bytes.Buffer
+	if err := jt.tmpl.Execute(&body, struct {
+		Filename         string
+		Advisory         string
+		ValidationStatus validationStatus
+		SHA256           string
+		SHA512           string
+	}{
+		Filename:         filepath.Base(adv.Filename),
+		Advisory:         adv.Doc,
+		ValidationStatus: adv.Status,
+		SHA256:           hashValue(adv.SHA256),
+		SHA512:           hashValue(adv.SHA512),
+	}); err != nil {
+		return fmt.Errorf("executing forward template failed: %w", err)
+	}
+	if !json.Valid(body.Bytes()) {
+		return errors.New("forward template does not result in valid JSON")
+	}
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, jt.f.cfg.ForwardURL, &body)
+	if err != nil {
+		return fmt.Errorf("building forward request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return jt.f.post(req, func(code int) bool { return code >= 200 && code < 300 })
+}
+
+// deliver implements [forwardTarget].
+// The advisory is written last so that consumers watching
+// the directory find the other files already in place.
+func (st *spoolTarget) deliver(_ context.Context, adv *forwardedAdvisory) error {
+	if err := os.MkdirAll(st.dir, 0755); err != nil {
+		return err
+	}
+	base := filepath.Base(adv.Filename)
+	for _, x := range []struct {
+		name    string
+		content string
+	}{
+		{base + ".sha256", adv.SHA256},
+		{base + ".sha512", adv.SHA512},
+		{base + ".status", string(adv.Status)},
+		{base, adv.Doc},
+	} {
+		if x.content == "" {
+			continue
+		}
+		if err := writeFileAtomic(filepath.Join(st.dir, x.name), []byte(x.content)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file
+// which is renamed to the given name afterwards.
+func writeFileAtomic(fname string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(fname), "."+filepath.Base(fname)+"-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Chmod(tmp, 0644); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, fname); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// deliver implements [forwardTarget].
+// The advisory is passed to the command on STDIN. The other
+// information is passed in environment variables.
+func (ct *commandTarget) deliver(ctx context.Context, adv *forwardedAdvisory) error {
+	cmd := exec.CommandContext(ctx, ct.args[0], ct.args[1:]...)
+	cmd.Stdin = strings.NewReader(adv.Doc)
+	cmd.Env = append(os.Environ(),
+		"CSAF_FILENAME="+filepath.Base(adv.Filename),
+		"CSAF_VALIDATION_STATUS="+string(adv.Status),
+		"CSAF_SHA256="+hashValue(adv.SHA256),
+		"CSAF_SHA512="+hashValue(adv.SHA512),
+	)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		msg, _ := limitedString(&out, 512)
+		return fmt.Errorf("forward command failed: %w: %s", err, msg)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gocsaf/csaf/v3/internal/options"
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+// testForwarded is the advisory forwarded in the tests.
+var testForwarded = &forwardedAdvisory{
+	Filename: "test.json",
+	Doc:      `{"document":{}}`,
+	Status:   validValidationStatus,
+	SHA256:   "abc  test.json\n",
+	SHA512:   "def  test.json\n",
+}
+
+func TestJSONTarget(t *testing.T) {
+	var got struct {
+		Filename         string          `json:"filename"`
+		ValidationStatus string          `json:"validation_status"`
+		SHA256           string          `json:"sha256"`
+		SHA512           string          `json:"sha512"`
+		Advisory         json.RawMessage `json:"advisory"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("unexpected content type %q", ct)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := &config{
+		LogLevel:      &options.LogLevel{Level: slog.LevelError},
+		ForwardTarget: forwardJSON,
+		ForwardURL:    server.URL,
+	}
+	if err := cfg.prepareForwarding(); err != nil {
+		t.Fatal(err)
+	}
+	fw := newForwarder(cfg)
+	if err := fw.send(context.Background(), testForwarded); err != nil {
+		t.Fatal(err)
+	}
+	if got.Filename != "test.json" || got.ValidationStatus != "valid" ||
+		got.SHA256 != "abc" || got.SHA512 != "def" ||
+		string(got.Advisory) != testForwarded.Doc {
+		t.Errorf("unexpected body %+v", got)
+	}
+
+	// A template producing invalid JSON.
+	tmpl := filepath.Join(t.TempDir(), "broken.tmpl")
+	if err := os.WriteFile(tmpl, []byte(`{"name": {{ .Filename }}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg.ForwardTemplate = tmpl
+	if err := cfg.prepareForwarding(); err != nil {
+		t.Fatal(err)
+	}
+	if err := newForwarder(cfg).send(context.Background(), testForwarded); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestSpoolTarget(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "spool")
+	st := &spoolTarget{dir: dir}
+	if err := st.deliver(context.Background(), testForwarded); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		"test.json":        testForwarded.Doc,
+		"test.json.sha256": testForwarded.SHA256,
+		"test.json.sha512": testForwarded.SHA512,
+		"test.json.status": "valid",
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("%s: got %q, want %q", name, data, want)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Errorf("unexpected number of files %d", len(entries))
+	}
+}
+
+func TestCommandTarget(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no shell available")
+	}
+	out := filepath.Join(t.TempDir(), "out")
+	ct := &commandTarget{args: []string{
+		sh, "-c", `cat > "$0" && echo "$CSAF_FILENAME $CSAF_VALIDATION_STATUS $CSAF_SHA256" >> "$0"`, out,
+	}}
+	if err := ct.deliver(context.Background(), testForwarded); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := testForwarded.Doc + "test.json valid abc\n"; string(data) != want {
+		t.Errorf("got %q, want %q", data, want)
+	}
+
+	ct = &commandTarget{args: []string{sh, "-c", "echo broken; exit 1"}}
+	if err := ct.deliver(context.Background(), testForwarded); err == nil {
+		t.Error("expected error for failing command")
+	}
+}
+
+// closeTracker is a response body which records if it was closed.
+type closeTracker struct {
+	io.Reader
+	closed bool
+}
+
+func (ct *closeTracker) Close() error {
+	ct.closed = true
+	return nil
+}
+
+// bodyClient answers every request with the given status code.
+type bodyClient struct {
+	util.ClientWithContext
+	code int
+	body *closeTracker
+}
+
+func (bc *bodyClient) Do(*http.Request) (*http.Response, error) {
+	bc.body = &closeTracker{Reader: strings.NewReader("response")}
+	return &http.Response{
+		Status:     http.StatusText(bc.code),
+		StatusCode: bc.code,
+		Body:       bc.body,
+	}, nil
+}
+
+func TestForwarderPostClosesBody(t *testing.T) {
+	for _, code := range []int{http.StatusCreated, http.StatusBadRequest} {
+		fw := newForwarder(&config{ForwardURL: "http://example.com"})
+		client := &bodyClient{code: code}
+		fw.client = client
+		err := fw.send(context.Background(), testForwarded)
+		if failed := err != nil; failed != (code != http.StatusCreated) {
+			t.Errorf("status code %d: unexpected error %v", code, err)
+		}
+		if !client.body.closed {
+			t.Errorf("status code %d: response body not closed", code)
+		}
+	}
+}
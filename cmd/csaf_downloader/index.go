@@ -0,0 +1,355 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"time"
+
+	_ "modernc.org/sqlite" // Register SQLite driver.
+
+	"github.com/gocsaf/csaf/v3/csaf"
+)
+
+// indexVersion is the version of the database schema.
+// It is stored as user_version in the database.
+const indexVersion = 1
+
+// indexSchema creates the tables of the advisory index.
+const indexSchema = `
+CREATE TABLE IF NOT EXISTS advisories (
+  id                   INTEGER PRIMARY KEY,
+  url                  TEXT NOT NULL,
+  path                 TEXT NOT NULL,
+  tracking_id          TEXT NOT NULL,
+  version              TEXT,
+  category             TEXT,
+  title                TEXT,
+  summary              TEXT,
+  tlp                  TEXT,
+  status               TEXT,
+  publisher_name       TEXT,
+  publisher_namespace  TEXT NOT NULL,
+  publisher_category   TEXT,
+  initial_release_date TEXT,
+  current_release_date TEXT,
+  indexed              TEXT NOT NULL,
+  UNIQUE (publisher_namespace, tracking_id)
+);
+
+CREATE TABLE IF NOT EXISTS vulnerabilities (
+  id       INTEGER PRIMARY KEY,
+  advisory INTEGER NOT NULL REFERENCES advisories(id) ON DELETE CASCADE,
+  cve      TEXT,
+  cwe      TEXT,
+  title    TEXT
+);
+CREATE INDEX IF NOT EXISTS vulnerabilities_advisory ON vulnerabilities(advisory);
+CREATE INDEX IF NOT EXISTS vulnerabilities_cve ON vulnerabilities(cve);
+
+CREATE TABLE IF NOT EXISTS products (
+  advisory   INTEGER NOT NULL REFERENCES advisories(id) ON DELETE CASCADE,
+  product_id TEXT NOT NULL,
+  name       TEXT,
+  PRIMARY KEY (advisory, product_id)
+);
+
+CREATE TABLE IF NOT EXISTS product_identifiers (
+  advisory   INTEGER NOT NULL REFERENCES advisories(id) ON DELETE CASCADE,
+  product_id TEXT NOT NULL,
+  type       TEXT NOT NULL CHECK (type IN ('purl', 'cpe')),
+  value      TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS product_identifiers_advisory ON product_identifiers(advisory);
+CREATE INDEX IF NOT EXISTS product_identifiers_value ON product_identifiers(value);
+
+CREATE TABLE IF NOT EXISTS product_status (
+  vulnerability INTEGER NOT NULL REFERENCES vulnerabilities(id) ON DELETE CASCADE,
+  product_id    TEXT NOT NULL,
+  status        TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS product_status_vulnerability ON product_status(vulnerability);
+CREATE INDEX IF NOT EXISTS product_status_product_id ON product_status(product_id);
+`
+
+// advisoryIndex is a SQLite database of the stored advisories.
+type advisoryIndex struct {
+	db *sql.DB
+}
+
+// openAdvisoryIndex opens the index in the given file
+// and creates the tables if needed.
+func openAdvisoryIndex(fname string) (*advisoryIndex, error) {
+	dsn := "file:" + url.PathEscape(fname) +
+		"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite does not allow concurrent writers.
+	db.SetMaxOpenConns(1)
+
+	var version int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if version > indexVersion {
+		db.Close()
+		return nil, fmt.Errorf(
+			"index has newer version %d than supported %d", version, indexVersion)
+	}
+	if _, err := db.Exec(indexSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if _, err := db.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, indexVersion)); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &advisoryIndex{db: db}, nil
+}
+
+// close closes the underlying database.
+func (ai *advisoryIndex) close() error {
+	return ai.db.Close()
+}
+
+// remove removes the advisory stored at the given path.
+func (ai *advisoryIndex) remove(path string) error {
+	_, err := ai.db.Exec(`DELETE FROM advisories WHERE path = ?`, path)
+	return err
+}
+
+// productStatus returns the product ids by their status.
+func productStatus(ps *csaf.ProductStatus) map[string]*csaf.Products {
+	if ps == nil {
+		return nil
+	}
+	return map[string]*csaf.Products{
+		"first_affected":      ps.FirstAffected,
+		"first_fixed":         ps.FirstFixed,
+		"fixed":               ps.Fixed,
+		"known_affected":      ps.KnownAffected,
+		"known_not_affected":  ps.KnownNotAffected,
+		"last_affected":       ps.LastAffected,
+		"recommended":         ps.Recommended,
+		"under_investigation": ps.UnderInvestigation,
+	}
+}
+
+// visitFullProductNames calls visit for all full product names
+// found in the product tree of the given advisory.
+func visitFullProductNames(adv *csaf.Advisory, visit func(*csaf.FullProductName)) {
+	pt := adv.ProductTree
+	if pt == nil {
+		return
+	}
+	if pt.FullProductNames != nil {
+		for _, fpn := range *pt.FullProductNames {
+			if fpn != nil && fpn.ProductID != nil {
+				visit(fpn)
+			}
+		}
+	}
+	var recBranch func(*csaf.Branch)
+	recBranch = func(b *csaf.Branch) {
+		if b == nil {
+			return
+		}
+		if fpn := b.Product; fpn != nil && fpn.ProductID != nil {
+			visit(fpn)
+		}
+		for _, c := range b.Branches {
+			recBranch(c)
+		}
+	}
+	for _, b := range pt.Branches {
+		recBranch(b)
+	}
+	if pt.RelationShips != nil {
+		for _, rel := range *pt.RelationShips {
+			if rel != nil && rel.FullProductName != nil && rel.FullProductName.ProductID != nil {
+				visit(rel.FullProductName)
+			}
+		}
+	}
+}
+
+// str returns the string pointed to or nil.
+func str[S ~string](s *S) any {
+	if s == nil {
+		return nil
+	}
+	return string(*s)
+}
+
+// store replaces the entries of the given advisory in the index.
+func (ai *advisoryIndex) store(
+	u, path string,
+	sum *csaf.AdvisorySummary,
+	adv *csaf.Advisory,
+) error {
+	tx, err := ai.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	namespace := str(sum.Publisher.Namespace)
+	if namespace == nil {
+		namespace = ""
+	}
+
+	if _, err := tx.Exec(
+		`DELETE FROM advisories WHERE publisher_namespace = ? AND tracking_id = ?`,
+		namespace, sum.ID,
+	); err != nil {
+		return err
+	}
+
+	var version, category any
+	if doc := adv.Document; doc != nil {
+		category = str(doc.Category)
+		if doc.Tracking != nil {
+			version = str(doc.Tracking.Version)
+		}
+	}
+	var pubCategory any
+	if sum.Publisher.Category != nil {
+		pubCategory = string(*sum.Publisher.Category)
+	}
+
+	res, err := tx.Exec(`INSERT INTO advisories (
+  url, path, tracking_id, version, category, title, summary, tlp, status,
+  publisher_name, publisher_namespace, publisher_category,
+  initial_release_date, current_release_date, indexed
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		u, path, sum.ID, version, category, sum.Title, sum.Summary, sum.TLPLabel, sum.Status,
+		str(sum.Publisher.Name), namespace, pubCategory,
+		sum.InitialReleaseDate.UTC().Format(time.RFC3339),
+		sum.CurrentReleaseDate.UTC().Format(time.RFC3339),
+		time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return err
+	}
+	advID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	var storeErr error
+	visitFullProductNames(adv, func(fpn *csaf.FullProductName) {
+		if storeErr != nil {
+			return
+		}
+		pid := string(*fpn.ProductID)
+		if _, storeErr = tx.Exec(
+			`INSERT OR IGNORE INTO products (advisory, product_id, name) VALUES (?, ?, ?)`,
+			advID, pid, fpn.Name,
+		); storeErr != nil {
+			return
+		}
+		pih := fpn.ProductIdentificationHelper
+		if pih == nil {
+			return
+		}
+		for _, id := range []struct {
+			typ   string
+			value any
+		}{
+			{"purl", str(pih.PURL)},
+			{"cpe", str(pih.CPE)},
+		} {
+			if id.value == nil {
+				continue
+			}
+			if _, storeErr = tx.Exec(
+				`INSERT INTO product_identifiers (advisory, product_id, type, value) VALUES (?, ?, ?, ?)`,
+				advID, pid, id.typ, id.value,
+			); storeErr != nil {
+				return
+			}
+		}
+	})
+	if storeErr != nil {
+		return storeErr
+	}
+
+	for _, vuln := range adv.Vulnerabilities {
+		if vuln == nil {
+			continue
+		}
+		var cwe any
+		if vuln.CWE != nil {
+			cwe = str(vuln.CWE.ID)
+		}
+		res, err := tx.Exec(
+			`INSERT INTO vulnerabilities (advisory, cve, cwe, title) VALUES (?, ?, ?, ?)`,
+			advID, str(vuln.CVE), cwe, vuln.Title,
+		)
+		if err != nil {
+			return err
+		}
+		vulnID, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		for status, products := range productStatus(vuln.ProductStatus) {
+			if products == nil {
+				continue
+			}
+			for _, pid := range *products {
+				if pid == nil {
+					continue
+				}
+				if _, err := tx.Exec(
+					`INSERT INTO product_status (vulnerability, product_id, status) VALUES (?, ?, ?)`,
+					vulnID, string(*pid), status,
+				); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// indexAdvisory adds a stored advisory to the index if configured.
+func (dc *downloadContext) indexAdvisory(
+	file csaf.AdvisoryFile,
+	data []byte,
+	doc any,
+	path string,
+) {
+	if dc.d.index == nil {
+		return
+	}
+	if err := func() error {
+		sum, err := csaf.NewAdvisorySummary(dc.expr, doc)
+		if err != nil {
+			return err
+		}
+		var adv csaf.Advisory
+		if err := json.Unmarshal(data, &adv); err != nil {
+			return err
+		}
+		return dc.d.index.store(file.URL(), path, sum, &adv)
+	}(); err != nil {
+		slog.Error("Indexing advisory failed",
+			"url", file.URL(),
+			"error", err)
+	}
+}
@@ -0,0 +1,86 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gocsaf/csaf/v3/internal/options"
+	"github.com/gocsaf/csaf/v3/internal/testutil"
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+func TestAdvisoryIndex(t *testing.T) {
+	params := testutil.ProviderParams{
+		EnableSha256: true,
+		EnableSha512: true,
+	}
+	server := httptest.NewTLSServer(testutil.ProviderHandler(&params, false))
+	defer server.Close()
+	params.URL = server.URL
+
+	client := util.Client(server.Client())
+	dir := t.TempDir()
+
+	// Download twice to check that the entries are replaced.
+	for range 2 {
+		cfg := config{
+			LogLevel:  &options.LogLevel{Level: slog.LevelError},
+			Directory: dir,
+			Index:     "index.db",
+		}
+		if err := cfg.prepare(); err != nil {
+			t.Fatal(err)
+		}
+		d, err := newDownloader(&cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.client = &client
+		err = d.run(context.Background(), []string{server.URL + "/provider-metadata.json"})
+		d.close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	db, err := sql.Open("sqlite", dir+"/index.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	count := func(query string, args ...any) int {
+		var n int
+		if err := db.QueryRow(query, args...).Scan(&n); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		return n
+	}
+
+	if n := count(`SELECT count(*) FROM advisories WHERE tracking_id = 'Avendor-advisory-0004'`); n != 1 {
+		t.Errorf("advisories: got %d, want 1", n)
+	}
+	if n := count(`SELECT count(*) FROM vulnerabilities WHERE cve = 'CVE-2020-1234'`); n != 1 {
+		t.Errorf("vulnerabilities: got %d, want 1", n)
+	}
+	if n := count(`SELECT count(*) FROM products WHERE product_id = 'CSAFPID_0001'`); n != 1 {
+		t.Errorf("products: got %d, want 1", n)
+	}
+	if n := count(`SELECT count(*) FROM product_status s
+JOIN vulnerabilities v ON s.vulnerability = v.id
+WHERE v.cve = 'CVE-2020-1234' AND s.product_id = 'CSAFPID_0001'
+AND s.status = 'under_investigation'`); n != 1 {
+		t.Errorf("product status: got %d, want 1", n)
+	}
+}
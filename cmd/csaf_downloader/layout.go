@@ -0,0 +1,109 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+// unknownLayoutValue replaces empty values in the layout.
+const unknownLayoutValue = "unknown"
+
+// layoutData are the values which can be used in the layout template.
+// All of them are safe to be used as a path component.
+type layoutData struct {
+	Namespace  string
+	Publisher  string
+	Domain     string
+	TLP        string
+	Year       string
+	TrackingID string
+	Category   string
+}
+
+// pathComponent turns a value into a single path component.
+// URL schemes are removed and all characters besides letters,
+// digits, '.', '-' and '_' are replaced by '_'.
+func pathComponent(s string) string {
+	if i := strings.Index(s, "://"); i >= 0 {
+		s = s[i+3:]
+	}
+	s = strings.Trim(s, "/")
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z',
+			'0' <= r && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, s)
+	if s == "" || strings.Trim(s, ".") == "" {
+		return unknownLayoutValue
+	}
+	return s
+}
+
+// compileLayout parses the template of the folder layout.
+func (cfg *config) compileLayout() error {
+	if cfg.Layout == "" {
+		return nil
+	}
+	if cfg.Folder != "" {
+		return errors.New("the options 'folder' and 'layout' cannot be combined")
+	}
+	tmpl, err := template.New("layout").Funcs(template.FuncMap{
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+	}).Option("missingkey=error").Parse(cfg.Layout)
+	if err != nil {
+		return fmt.Errorf("invalid layout: %w", err)
+	}
+	cfg.layout = tmpl
+	return nil
+}
+
+// layoutDir returns the folder relative to the download directory
+// the given advisory is stored in according to the configured layout.
+func (dc *downloadContext) layoutDir(doc any) (string, error) {
+	var namespace, publisher, trackingID, category string
+	dc.expr.Match([]util.PathEvalMatcher{
+		{Expr: `$.document.publisher.namespace`, Action: util.StringMatcher(&namespace), Optional: true},
+		{Expr: `$.document.publisher.name`, Action: util.StringMatcher(&publisher), Optional: true},
+		{Expr: `$.document.tracking.id`, Action: util.StringMatcher(&trackingID), Optional: true},
+		{Expr: `$.document.category`, Action: util.StringMatcher(&category), Optional: true},
+	}, doc)
+
+	data := layoutData{
+		Namespace:  pathComponent(namespace),
+		Publisher:  pathComponent(publisher),
+		Domain:     pathComponent(dc.domain),
+		TLP:        pathComponent(dc.lower),
+		Year:       strconv.Itoa(dc.initialReleaseDate.Year()),
+		TrackingID: pathComponent(trackingID),
+		Category:   pathComponent(category),
+	}
+
+	var b strings.Builder
+	if err := dc.d.cfg.layout.Execute(&b, &data); err != nil {
+		return "", fmt.Errorf("applying layout failed: %w", err)
+	}
+	dir := path.Clean(strings.TrimSpace(b.String()))
+	if dir == "." || path.IsAbs(dir) ||
+		dir == ".." || strings.HasPrefix(dir, "../") {
+		return "", fmt.Errorf("layout results in invalid folder %q", b.String())
+	}
+	return dir, nil
+}
@@ -0,0 +1,128 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gocsaf/csaf/v3/internal/options"
+	"github.com/gocsaf/csaf/v3/internal/testutil"
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+func TestPathComponent(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"https://example.com", "example.com"},
+		{"https://example.com/psirt/", "example.com_psirt"},
+		{"ACME Inc.", "ACME_Inc."},
+		{"../..", ".._.."},
+		{"..", "unknown"},
+		{"", "unknown"},
+	} {
+		if got := pathComponent(tc.in); got != tc.want {
+			t.Errorf("pathComponent(%q): got %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestCompileLayout(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		cfg    config
+		wantOK bool
+	}{
+		{"none", config{}, true},
+		{"valid", config{Layout: "{{.Domain}}/{{.TLP | upper}}"}, true},
+		{"invalid", config{Layout: "{{.Domain"}, false},
+		{"with folder", config{Layout: "{{.Domain}}", Folder: "x"}, false},
+	} {
+		if err := tc.cfg.compileLayout(); (err == nil) != tc.wantOK {
+			t.Errorf("%s: got %v", tc.name, err)
+		}
+	}
+}
+
+func TestLayout(t *testing.T) {
+	params := testutil.ProviderParams{
+		EnableSha256: true,
+		EnableSha512: true,
+	}
+	server := httptest.NewTLSServer(testutil.ProviderHandler(&params, false))
+	defer server.Close()
+	params.URL = server.URL
+
+	client := util.Client(server.Client())
+
+	for _, tc := range []struct {
+		name   string
+		layout string
+		want   string
+	}{
+		{
+			name:   "publisher",
+			layout: "{{.Namespace}}/{{.TLP}}/{{.Year}}",
+			want:   "www.example.com/white/2020",
+		},
+		{
+			name:   "advisory",
+			layout: "{{.Domain}}/{{.Category}}/{{.TrackingID | lower}}",
+			want:   "127.0.0.1/csaf_vex/avendor-advisory-0004",
+		},
+		{
+			name:   "escaping",
+			layout: "{{.Publisher}}/../..",
+			want:   "",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			cfg := config{
+				LogLevel:  &options.LogLevel{Level: slog.LevelError},
+				Directory: dir,
+				Layout:    tc.layout,
+			}
+			if err := cfg.prepare(); err != nil {
+				t.Fatal(err)
+			}
+			d, err := newDownloader(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer d.close()
+			d.client = &client
+
+			err = d.run(context.Background(),
+				[]string{server.URL + "/provider-metadata.json"})
+
+			if tc.want == "" {
+				if err == nil {
+					t.Fatal("invalid folder accepted")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(dir, filepath.FromSlash(tc.want), "avendor-advisory-0004.json")
+			// Hashes and signatures are stored next to the advisory.
+			for _, ext := range []string{"", ".sha256", ".sha512", ".asc"} {
+				if _, err := os.Stat(path + ext); err != nil {
+					t.Errorf("missing %s: %v", path+ext, err)
+				}
+			}
+		})
+	}
+}
@@ -11,6 +11,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -18,46 +19,69 @@ import (
 	"github.com/gocsaf/csaf/v3/internal/options"
 )
 
-func run(cfg *config, domains []string) error {
+// withDownloader creates a downloader with an optional forwarder
+// and calls fn with it. Afterwards the queue of the forwarder
+// is drained and the downloader is closed.
+func withDownloader(cfg *config, m *downloaderMetrics, fn func(*downloader) error) error {
 	d, err := newDownloader(cfg)
 	if err != nil {
 		return err
 	}
+	d.metrics = m
 	defer d.close()
+	// Write the report after the forwarder has finished.
+	defer d.writeReport()
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
-	defer stop()
-
-	if cfg.ForwardURL != "" {
+	if cfg.forwarding() {
 		f := newForwarder(cfg)
+		f.metrics = m
+		if err := f.openQueue(); err != nil {
+			return fmt.Errorf("opening forward queue failed: %w", err)
+		}
 		go f.run()
 		defer func() {
 			f.log()
 			f.close()
+			f.wait()
 		}()
 		d.forwarder = f
 	}
+	return fn(d)
+}
 
-	// If the enumerate-only flag is set, enumerate found PMDs,
-	// else use the normal load method
-	if cfg.EnumeratePMDOnly {
-		return d.runEnumerate(ctx, domains)
+func run(cfg *config, domains []string) error {
+	if cfg.Daemon && !cfg.EnumeratePMDOnly {
+		return runAsDaemon(cfg, domains)
 	}
-	return d.run(ctx, domains)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	m := newDownloaderMetrics(cfg)
+	defer m.serve(cfg)()
+
+	return withDownloader(cfg, m, func(d *downloader) error {
+		// If the enumerate-only flag is set, enumerate found PMDs,
+		// else use the normal load method
+		if cfg.EnumeratePMDOnly {
+			return d.runEnumerate(ctx, domains)
+		}
+		return d.run(ctx, domains)
+	})
 }
 
 func main() {
 
 	domains, cfg, err := parseArgsConfig()
 	options.ErrorCheck(err)
-	options.ErrorCheck(cfg.prepare())
+	options.ErrorCheckStructured(cfg.prepare())
 
-	if len(domains) == 0 {
+	if len(domains) == 0 && len(cfg.Aggregators) == 0 {
 		slog.Warn("No domains given.")
 		return
 	}
 
-	options.ErrorCheck(run(cfg, domains))
+	options.ErrorCheckStructured(run(cfg, domains))
 }
@@ -0,0 +1,82 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import "github.com/gocsaf/csaf/v3/internal/metrics"
+
+// downloaderMetrics are the metrics exposed by the downloader.
+// They outlive the downloaders created on reloads in daemon mode.
+type downloaderMetrics struct {
+	registry  *metrics.Registry
+	requests  *metrics.Counter
+	processed *metrics.Counter
+	failed    *metrics.Counter
+	queue     *metrics.Gauge
+}
+
+// newDownloaderMetrics creates the metrics if
+// the metrics endpoint is configured.
+func newDownloaderMetrics(cfg *config) *downloaderMetrics {
+	if cfg.MetricsAddress == "" {
+		return nil
+	}
+	r := metrics.NewRegistry()
+	return &downloaderMetrics{
+		registry:  r,
+		requests:  r.Requests(),
+		processed: r.Processed(),
+		failed:    r.Failed(),
+		queue:     r.QueueDepth(),
+	}
+}
+
+// serve starts the metrics endpoint.
+// The returned function shuts it down.
+func (m *downloaderMetrics) serve(cfg *config) func() {
+	if m == nil {
+		return func() {}
+	}
+	return m.registry.Serve(cfg.MetricsAddress)
+}
+
+// count counts the changes of the stats
+// while downloading an advisory.
+func (m *downloaderMetrics) count(before, after *stats) {
+	if m == nil {
+		return
+	}
+	for _, c := range []struct {
+		counter *metrics.Counter
+		label   string
+		n       int
+	}{
+		{m.processed, "succeeded", after.succeeded - before.succeeded},
+		{m.processed, "not_modified", after.notModified - before.notModified},
+		{m.processed, "filtered", after.filtered - before.filtered},
+		{m.processed, "failed", after.totalFailed() - before.totalFailed()},
+		{m.failed, "download", after.downloadFailed - before.downloadFailed},
+		{m.failed, "filename", after.filenameFailed - before.filenameFailed},
+		{m.failed, "schema", after.schemaFailed - before.schemaFailed},
+		{m.failed, "remote", after.remoteFailed - before.remoteFailed},
+		{m.failed, "sha256", after.sha256Failed - before.sha256Failed},
+		{m.failed, "sha512", after.sha512Failed - before.sha512Failed},
+		{m.failed, "signature", after.signatureFailed - before.signatureFailed},
+	} {
+		if c.n > 0 {
+			c.counter.Add(float64(c.n), c.label)
+		}
+	}
+}
+
+// queued sets the depth of a queue.
+func (m *downloaderMetrics) queued(queue string, n int) {
+	if m != nil {
+		m.queue.Set(float64(n), queue)
+	}
+}
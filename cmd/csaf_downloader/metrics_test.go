@@ -0,0 +1,73 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gocsaf/csaf/v3/internal/options"
+	"github.com/gocsaf/csaf/v3/internal/testutil"
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+func TestMetrics(t *testing.T) {
+	params := testutil.ProviderParams{
+		EnableSha256: true,
+		EnableSha512: false,
+	}
+	server := httptest.NewTLSServer(testutil.ProviderHandler(&params, false))
+	defer server.Close()
+	params.URL = server.URL
+
+	cfg := config{
+		LogLevel:       &options.LogLevel{Level: slog.LevelError},
+		Directory:      t.TempDir(),
+		MetricsAddress: "localhost:0",
+	}
+	if err := cfg.prepare(); err != nil {
+		t.Fatal(err)
+	}
+	m := newDownloaderMetrics(&cfg)
+
+	d, err := newDownloader(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.close()
+	client := util.Client(server.Client())
+	d.client = &client
+	d.metrics = m
+
+	if err := d.run(context.Background(),
+		[]string{server.URL + "/provider-metadata.json"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	if err := m.registry.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, line := range []string{
+		`csaf_advisories_processed_total{result="succeeded"} 1`,
+		`csaf_http_requests_total{host="127.0.0.1",status="200"} `,
+		`csaf_queue_depth{queue="advisories"} 0`,
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("missing %q in:\n%s", line, out)
+		}
+	}
+	if strings.Contains(out, "csaf_advisories_failed_total{") {
+		t.Errorf("unexpected failures:\n%s", out)
+	}
+}
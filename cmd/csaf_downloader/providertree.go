@@ -0,0 +1,166 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gocsaf/csaf/v3/csaf"
+	"github.com/gocsaf/csaf/v3/internal/indices"
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+// tlpLabels are the names of the label folders of a provider tree.
+var tlpLabels = []string{
+	csaf.TLPLabelUnlabeled,
+	csaf.TLPLabelWhite,
+	csaf.TLPLabelGreen,
+	csaf.TLPLabelAmber,
+	csaf.TLPLabelRed,
+}
+
+// prepareProviderTree checks the base URL of the provider tree.
+func (cfg *config) prepareProviderTree() error {
+	if cfg.ProviderTree == "" {
+		return nil
+	}
+	if cfg.Folder != "" || cfg.Layout != "" {
+		return errors.New("the option 'provider_tree' cannot be combined with 'folder' or 'layout'")
+	}
+	u, err := url.Parse(cfg.ProviderTree)
+	if err != nil {
+		return fmt.Errorf("invalid provider tree URL: %w", err)
+	}
+	if !u.IsAbs() {
+		return fmt.Errorf("provider tree URL %q is not absolute", cfg.ProviderTree)
+	}
+	cfg.providerTree = u
+	return nil
+}
+
+// ensureHash writes the hash file of an advisory if it is missing.
+func ensureHash(path, ext string, data []byte, h hash.Hash) error {
+	if _, err := os.Stat(path + ext); !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	h.Write(data)
+	return indices.WriteHash(path+ext, filepath.Base(path), h.Sum(nil))
+}
+
+// treeSummaries collects the summaries of the valid advisories
+// stored in <label>/<year>/<file> below the download directory.
+// Missing hashes are created on the way.
+func (d *downloader) treeSummaries() (map[string][]indices.Summary, error) {
+	expr := util.NewPathEval()
+	summaries := map[string][]indices.Summary{}
+
+	for _, label := range tlpLabels {
+		label = strings.ToLower(label)
+		labelDir := filepath.Join(d.cfg.Directory, label)
+		years, err := os.ReadDir(labelDir)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		// Keep the label to update the indices of emptied folders.
+		ss := []indices.Summary{}
+		for _, year := range years {
+			if _, err := strconv.Atoi(year.Name()); err != nil || !year.IsDir() {
+				continue
+			}
+			yearDir := filepath.Join(labelDir, year.Name())
+			files, err := os.ReadDir(yearDir)
+			if err != nil {
+				return nil, err
+			}
+			for _, file := range files {
+				name := file.Name()
+				if file.IsDir() || filepath.Ext(name) != ".json" ||
+					!util.ConformingFileName(name) {
+					continue
+				}
+				path := filepath.Join(yearDir, name)
+				data, err := os.ReadFile(path)
+				if err != nil {
+					return nil, err
+				}
+				var doc any
+				if err := json.Unmarshal(data, &doc); err != nil {
+					slog.Warn("Not listing unreadable advisory",
+						"path", path,
+						"error", err)
+					continue
+				}
+				sum, err := csaf.NewAdvisorySummary(expr, doc)
+				if err != nil {
+					slog.Warn("Not listing advisory",
+						"path", path,
+						"error", err)
+					continue
+				}
+				if y := strconv.Itoa(sum.InitialReleaseDate.Year()); y != year.Name() {
+					slog.Warn("Not listing advisory stored in wrong year folder",
+						"path", path,
+						"year", y)
+					continue
+				}
+				if err := ensureHash(path, ".sha256", data, sha256.New()); err != nil {
+					return nil, err
+				}
+				if err := ensureHash(path, ".sha512", data, sha512.New()); err != nil {
+					return nil, err
+				}
+				_, err = os.Stat(path + ".asc")
+				ss = append(ss, indices.Summary{
+					Filename: name,
+					Summary:  sum,
+					Unsigned: err != nil,
+				})
+			}
+		}
+		summaries[label] = ss
+	}
+	return summaries, nil
+}
+
+// writeProviderTree updates index.txt, changes.csv, the ROLIE
+// feeds and the service document of the stored advisories
+// so that the download directory can be served like a provider.
+func (d *downloader) writeProviderTree() error {
+	if d.cfg.providerTree == nil || d.cfg.NoStore {
+		return nil
+	}
+	summaries, err := d.treeSummaries()
+	if err != nil {
+		return fmt.Errorf("collecting advisories of provider tree failed: %w", err)
+	}
+	iw := &indices.Writer{
+		Dir:     d.cfg.Directory,
+		BaseURL: d.cfg.providerTree,
+		Indices: true,
+		Service: true,
+	}
+	if err := iw.Write(summaries, nil); err != nil {
+		return fmt.Errorf("writing provider tree failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gocsaf/csaf/v3/csaf"
+	"github.com/gocsaf/csaf/v3/internal/options"
+	"github.com/gocsaf/csaf/v3/internal/testutil"
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+func TestProviderTree(t *testing.T) {
+	params := testutil.ProviderParams{
+		EnableSha256: true,
+	}
+	server := httptest.NewTLSServer(testutil.ProviderHandler(&params, false))
+	defer server.Close()
+	params.URL = server.URL
+
+	client := util.Client(server.Client())
+	dir := t.TempDir()
+
+	cfg := config{
+		LogLevel:     &options.LogLevel{Level: slog.LevelError},
+		Directory:    dir,
+		ProviderTree: "https://csaf.example.com/local",
+		Prune:        pruneQuarantine,
+	}
+	if err := cfg.prepare(); err != nil {
+		t.Fatal(err)
+	}
+	d, err := newDownloader(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.close()
+	d.client = &client
+
+	// Run twice to check the feeds are not pruned.
+	for range 2 {
+		if err := d.run(context.Background(),
+			[]string{server.URL + "/provider-metadata.json"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	white := filepath.Join(dir, "white")
+	advisory := filepath.Join(white, "2020", "avendor-advisory-0004.json")
+
+	// The missing SHA512 hash is created.
+	for _, p := range []string{
+		advisory + ".sha512",
+		filepath.Join(white, "changes.csv"),
+		filepath.Join(dir, "service.json"),
+	} {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("missing %s: %v", p, err)
+		}
+	}
+
+	index, err := os.ReadFile(filepath.Join(white, "index.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(index), "2020/avendor-advisory-0004.json\n"; got != want {
+		t.Errorf("index.txt: got %q, want %q", got, want)
+	}
+
+	data, err := os.ReadFile(filepath.Join(white, "csaf-feed-tlp-white.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var feed csaf.ROLIEFeed
+	if err := json.Unmarshal(data, &feed); err != nil {
+		t.Fatal(err)
+	}
+	if len(feed.Feed.Entry) != 1 {
+		t.Fatalf("feed entries: got %d, want 1", len(feed.Feed.Entry))
+	}
+	if got, want := feed.Feed.Entry[0].Content.Src,
+		"https://csaf.example.com/local/white/2020/avendor-advisory-0004.json"; got != want {
+		t.Errorf("feed entry: got %q, want %q", got, want)
+	}
+}
+
+func TestPrepareProviderTree(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		cfg    config
+		wantOK bool
+	}{
+		{"none", config{}, true},
+		{"valid", config{ProviderTree: "https://example.com/csaf"}, true},
+		{"relative", config{ProviderTree: "csaf"}, false},
+		{"with layout", config{ProviderTree: "https://example.com", Layout: "{{.Domain}}"}, false},
+	} {
+		if err := tc.cfg.prepareProviderTree(); (err == nil) != tc.wantOK {
+			t.Errorf("%s: got %v", tc.name, err)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gocsaf/csaf/v3/csaf"
+	"github.com/gocsaf/csaf/v3/internal/indices"
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+// quarantineDir is the name of the sub folder where
+// pruned advisories are moved to.
+const quarantineDir = "quarantine"
+
+// pruneMode is how advisories are pruned.
+type pruneMode string
+
+const (
+	pruneQuarantine = pruneMode("quarantine")
+	pruneDelete     = pruneMode("delete")
+)
+
+// pruneReason is why an advisory is pruned.
+type pruneReason string
+
+const (
+	pruneVanished  = pruneReason("vanished")
+	pruneWithdrawn = pruneReason("withdrawn")
+)
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (pm *pruneMode) UnmarshalText(text []byte) error {
+	switch m := pruneMode(text); m {
+	case pruneQuarantine, pruneDelete:
+		*pm = m
+	default:
+		return fmt.Errorf(`invalid value %q (expected "quarantine" or "delete")`, m)
+	}
+	return nil
+}
+
+// UnmarshalFlag implements [flags.UnmarshalFlag].
+func (pm *pruneMode) UnmarshalFlag(value string) error {
+	return pm.UnmarshalText([]byte(value))
+}
+
+// listAdvisories returns the filenames of all advisories currently
+// listed by a provider. An error is returned if not all of its
+// ROLIE feeds or directories could be read.
+func (d *downloader) listAdvisories(
+	ctx context.Context,
+	client util.Client,
+	doc any,
+	pmdURL *url.URL,
+) (map[string]bool, error) {
+	expr := util.NewPathEval()
+
+	// The processor skips feeds which cannot be loaded.
+	// Count them to detect incomplete listings.
+	var expected int
+	if rolie, err := expr.Eval("$.distributions[*].rolie.feeds", doc); err == nil {
+		var feeds [][]csaf.Feed
+		if err := util.ReMarshalJSON(&feeds, rolie); err != nil {
+			return nil, err
+		}
+		for _, fs := range feeds {
+			for i := range fs {
+				if fs[i].URL != nil {
+					expected++
+				}
+			}
+		}
+	}
+
+	afp := csaf.NewAdvisoryFileProcessor(client, expr, doc, pmdURL)
+	afp.StreamingROLIEParser = d.cfg.StreamingROLIEParser
+
+	listed := map[string]bool{}
+	var loaded int
+	if err := afp.ProcessWithContext(ctx, func(_ csaf.TLPLabel, files []csaf.AdvisoryFile) error {
+		loaded++
+		for _, file := range files {
+			u, err := url.Parse(file.URL())
+			if err != nil {
+				continue
+			}
+			listed[filepath.Base(u.Path)] = true
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if loaded < expected {
+		return nil, fmt.Errorf("only %d of %d feeds could be loaded", loaded, expected)
+	}
+	return listed, nil
+}
+
+// updateListing records the advisories currently listed by the
+// provider of a domain. The listing of the domain is dropped if
+// this fails so that nothing is pruned based on it.
+func (d *downloader) updateListing(
+	ctx context.Context,
+	domain string,
+	client util.Client,
+	doc any,
+	pmdURL *url.URL,
+) {
+	if d.cfg.Prune == "" || d.cfg.NoStore {
+		return
+	}
+	listed, err := d.listAdvisories(ctx, client, doc, pmdURL)
+	if err != nil {
+		slog.Error("Listing advisories for pruning failed",
+			"domain", domain,
+			"error", err)
+		delete(d.listings, domain)
+		return
+	}
+	d.listings[domain] = listed
+}
+
+// withdrawn returns true if the stored advisory is withdrawn.
+func withdrawn(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	var doc struct {
+		Document struct {
+			Category string `json:"category"`
+		} `json:"document"`
+	}
+	if err := json.NewDecoder(f).Decode(&doc); err != nil {
+		return false, err
+	}
+	return doc.Document.Category == "csaf_withdrawn", nil
+}
+
+// pruneCandidate is a stored advisory to be pruned.
+type pruneCandidate struct {
+	path   string
+	reason pruneReason
+}
+
+// pruneCandidates returns the stored advisories
+// which are not listed or withdrawn.
+func (d *downloader) pruneCandidates(listed map[string]bool) ([]pruneCandidate, error) {
+	root := d.cfg.Directory
+	var candidates []pruneCandidate
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			switch {
+			case rel == failedValidationDir,
+				rel == failedForwardDir,
+				rel == quarantineDir,
+				entry.Name() == revisionsDir:
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		// Advisories are always stored in sub folders.
+		name := entry.Name()
+		if !strings.ContainsRune(rel, filepath.Separator) ||
+			!util.ConformingFileName(name) {
+			return nil
+		}
+		// Skip the ROLIE feeds of a provider tree.
+		if d.cfg.providerTree != nil && indices.IsIndexFile(name) &&
+			!strings.ContainsRune(filepath.Dir(rel), filepath.Separator) {
+			return nil
+		}
+		if !listed[name] {
+			candidates = append(candidates, pruneCandidate{path, pruneVanished})
+			return nil
+		}
+		if w, err := withdrawn(path); err != nil {
+			slog.Warn("Cannot read stored advisory",
+				"path", path,
+				"error", err)
+		} else if w {
+			candidates = append(candidates, pruneCandidate{path, pruneWithdrawn})
+		}
+		return nil
+	})
+	return candidates, err
+}
+
+// prune reconciles the stored advisories with the current listings
+// of the providers of the given domains. Advisories which are not
+// listed any more or withdrawn are moved to the quarantine folder
+// or deleted. Nothing is pruned if a listing is missing.
+func (d *downloader) prune(domains []string) error {
+	if d.cfg.Prune == "" || d.cfg.NoStore {
+		return nil
+	}
+	if d.aggregatorFailed {
+		slog.Warn("Not pruning as not all aggregators could be loaded")
+		return nil
+	}
+	listed := map[string]bool{}
+	for _, domain := range domains {
+		l, ok := d.listings[domain]
+		if !ok {
+			slog.Warn("Not pruning as listing of domain is incomplete",
+				"domain", domain)
+			return nil
+		}
+		maps.Copy(listed, l)
+	}
+
+	candidates, err := d.pruneCandidates(listed)
+	if err != nil {
+		return fmt.Errorf("searching advisories to prune failed: %w", err)
+	}
+
+	action := string(d.cfg.Prune)
+	if d.cfg.PruneDryRun {
+		action = "none"
+	}
+
+	var pruned int
+	for _, c := range candidates {
+		if d.cfg.PruneDryRun {
+			slog.Info("Would prune advisory",
+				"path", c.path,
+				"reason", c.reason,
+				"mode", d.cfg.Prune)
+			d.report.pruned(c.path, c.reason, action)
+			continue
+		}
+		if err := d.pruneAdvisory(c.path); err != nil {
+			return fmt.Errorf("pruning %q failed: %w", c.path, err)
+		}
+		if d.state != nil {
+			if err := d.state.pruned(c.path, c.reason); err != nil {
+				slog.Error("Updating synchronization state failed",
+					"path", c.path,
+					"error", err)
+			}
+		}
+		slog.Info("Pruned advisory",
+			"path", c.path,
+			"reason", c.reason,
+			"mode", d.cfg.Prune)
+		d.report.pruned(c.path, c.reason, action)
+		pruned++
+	}
+	if pruned > 0 {
+		slog.Info("Pruned advisories", "count", pruned)
+	}
+	return nil
+}
+
+// pruneAdvisory quarantines or deletes the advisory stored
+// at path together with its hash and signature files.
+func (d *downloader) pruneAdvisory(path string) error {
+	var dir string
+	if d.cfg.Prune == pruneQuarantine {
+		rel, err := filepath.Rel(d.cfg.Directory, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		dir = filepath.Join(d.cfg.Directory, quarantineDir, rel)
+		if err := d.mkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	for _, ext := range []string{"", ".sha256", ".sha512", ".asc"} {
+		var err error
+		if d.cfg.Prune == pruneQuarantine {
+			err = os.Rename(path+ext, filepath.Join(dir, filepath.Base(path)+ext))
+		} else {
+			err = os.Remove(path + ext)
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if d.index != nil {
+		if err := d.index.remove(path); err != nil {
+			slog.Error("Removing advisory from index failed",
+				"path", path,
+				"error", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gocsaf/csaf/v3/internal/options"
+	"github.com/gocsaf/csaf/v3/internal/testutil"
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+func TestPrune(t *testing.T) {
+	params := testutil.ProviderParams{
+		EnableSha256: true,
+		EnableSha512: true,
+	}
+	server := httptest.NewTLSServer(testutil.ProviderHandler(&params, false))
+	defer server.Close()
+	params.URL = server.URL
+
+	client := util.Client(server.Client())
+	dir := t.TempDir()
+	domains := []string{server.URL + "/provider-metadata.json"}
+
+	listed := filepath.Join(dir, "white", "2020", "avendor-advisory-0004.json")
+	stray := filepath.Join(dir, "white", "2020", "avendor-advisory-9999.json")
+	quarantined := filepath.Join(dir, quarantineDir, "white", "2020", "avendor-advisory-9999.json")
+
+	if err := os.MkdirAll(filepath.Dir(stray), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{stray, stray + ".sha256"} {
+		if err := os.WriteFile(p, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Files outside of the sub folders are no advisories.
+	if err := os.WriteFile(filepath.Join(dir, "avendor-advisory-9999.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	exists := func(p string) bool {
+		_, err := os.Stat(p)
+		return err == nil
+	}
+
+	newDownloader := func(dryRun bool) *downloader {
+		cfg := config{
+			LogLevel:    &options.LogLevel{Level: slog.LevelError},
+			Directory:   dir,
+			Prune:       pruneQuarantine,
+			PruneDryRun: dryRun,
+			Report:      "report.json",
+		}
+		if err := cfg.prepare(); err != nil {
+			t.Fatal(err)
+		}
+		d, err := newDownloader(&cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.client = &client
+		t.Cleanup(d.close)
+		return d
+	}
+
+	// A dry run does not touch anything.
+	d := newDownloader(true)
+	if err := d.run(context.Background(), domains); err != nil {
+		t.Fatal(err)
+	}
+	if !exists(stray) || exists(quarantined) {
+		t.Fatal("dry run pruned advisory")
+	}
+	if len(d.report.Pruned) != 1 ||
+		d.report.Pruned[0].Path != stray ||
+		d.report.Pruned[0].Reason != pruneVanished ||
+		d.report.Pruned[0].Action != "none" {
+		t.Errorf("unexpected dry run report: %+v", d.report.Pruned)
+	}
+
+	d = newDownloader(false)
+	if err := d.run(context.Background(), domains); err != nil {
+		t.Fatal(err)
+	}
+	if exists(stray) || !exists(quarantined) || !exists(quarantined+".sha256") {
+		t.Error("vanished advisory not quarantined")
+	}
+	if !exists(listed) {
+		t.Error("listed advisory pruned")
+	}
+	if !exists(filepath.Join(dir, "avendor-advisory-9999.json")) {
+		t.Error("file outside of sub folders pruned")
+	}
+
+	// Withdraw the listed advisory.
+	data, err := os.ReadFile(listed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = []byte(strings.Replace(string(data), `"csaf_vex"`, `"csaf_withdrawn"`, 1))
+	if err := os.WriteFile(listed, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.prune(domains); err != nil {
+		t.Fatal(err)
+	}
+	if exists(listed) {
+		t.Error("withdrawn advisory not pruned")
+	}
+	if last := d.report.Pruned[len(d.report.Pruned)-1]; last.Reason != pruneWithdrawn {
+		t.Errorf("reason: got %q, want %q", last.Reason, pruneWithdrawn)
+	}
+
+	// Nothing is pruned without a listing of all domains.
+	d = newDownloader(false)
+	if err := d.prune(domains); err != nil {
+		t.Fatal(err)
+	}
+	if d.report.Pruned != nil {
+		t.Errorf("pruned without listing: %+v", d.report.Pruned)
+	}
+
+	// Nor if an aggregator could not be loaded.
+	if err := os.WriteFile(stray, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	d = newDownloader(false)
+	d.cfg.Aggregators = []string{server.URL + "/missing/aggregator.json"}
+	if err := d.run(context.Background(), domains); err != nil {
+		t.Fatal(err)
+	}
+	if !exists(stray) {
+		t.Error("pruned with incomplete list of domains")
+	}
+	if err := d.run(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if !exists(stray) {
+		t.Error("pruned without domains")
+	}
+}
+
+func TestPruneWithdrawnSync(t *testing.T) {
+	params := testutil.ProviderParams{
+		EnableSha256: true,
+		EnableSha512: true,
+	}
+	provider := testutil.ProviderHandler(&params, false)
+	var advisoryGets int
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".json") && strings.Contains(r.URL.Path, "advisory") {
+			advisoryGets++
+		}
+		provider(w, r)
+	}))
+	defer server.Close()
+	params.URL = server.URL
+
+	client := util.Client(server.Client())
+	dir := t.TempDir()
+	pmdURL := server.URL + "/provider-metadata.json"
+	listed := filepath.Join(dir, "white", "2020", "avendor-advisory-0004.json")
+
+	sync := func() *downloader {
+		// Look at the advisory in every run.
+		state, err := openSyncState(filepath.Join(dir, "sync.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := state.setLastSync(pmdURL, time.Time{}); err != nil {
+			t.Fatal(err)
+		}
+		state.close()
+
+		cfg := config{
+			LogLevel:  &options.LogLevel{Level: slog.LevelError},
+			Directory: dir,
+			SyncState: "sync.db",
+			Prune:     pruneDelete,
+			Report:    "report.json",
+		}
+		if err := cfg.prepare(); err != nil {
+			t.Fatal(err)
+		}
+		d, err := newDownloader(&cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer d.close()
+		d.client = &client
+		if err := d.run(context.Background(), []string{pmdURL}); err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	if d := sync(); d.stats.succeeded != 1 || advisoryGets != 1 {
+		t.Fatalf("first run: succeeded %d, requests %d", d.stats.succeeded, advisoryGets)
+	}
+
+	// Let the stored advisory look withdrawn.
+	data, err := os.ReadFile(listed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = []byte(strings.Replace(string(data), `"csaf_vex"`, `"csaf_withdrawn"`, 1))
+	if err := os.WriteFile(listed, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if d := sync(); len(d.report.Pruned) != 1 || d.report.Pruned[0].Reason != pruneWithdrawn {
+		t.Fatalf("withdrawn advisory not pruned: %+v", d.report.Pruned)
+	}
+
+	// The pruned advisory is neither downloaded nor pruned again.
+	for run := range 2 {
+		d := sync()
+		if d.stats.succeeded != 0 || d.stats.notModified != 1 ||
+			len(d.report.Pruned) != 0 || advisoryGets != 1 {
+			t.Fatalf("run %d after pruning: succeeded %d, not modified %d, pruned %d, requests %d",
+				run, d.stats.succeeded, d.stats.notModified, len(d.report.Pruned), advisoryGets)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+//go:build !unix
+
+package main
+
+import (
+	"log/slog"
+	"os"
+)
+
+// notifyReload is not supported on this platform.
+func notifyReload(chan<- os.Signal) {
+	slog.Warn("Reloading the configuration on signal is not implemented on this platform.")
+}
+
+// terminationSignals are the signals which shut down the daemon.
+var terminationSignals = []os.Signal{os.Interrupt}
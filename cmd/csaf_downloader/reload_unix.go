@@ -0,0 +1,26 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyReload relays the signals requesting a reload
+// of the configuration to the given channel.
+func notifyReload(ch chan<- os.Signal) {
+	signal.Notify(ch, syscall.SIGHUP)
+}
+
+// terminationSignals are the signals which shut down the daemon.
+var terminationSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
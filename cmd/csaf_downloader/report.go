@@ -0,0 +1,263 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// advisoryOutcome is the result of processing an advisory.
+type advisoryOutcome string
+
+const (
+	outcomeDownloaded  = advisoryOutcome("downloaded")
+	outcomeNotModified = advisoryOutcome("not_modified")
+	outcomeIgnored     = advisoryOutcome("ignored")
+	outcomeFiltered    = advisoryOutcome("filtered")
+	outcomeFailed      = advisoryOutcome("failed")
+)
+
+// forwardOutcome is the result of forwarding an advisory.
+type forwardOutcome string
+
+const (
+	forwardPending   = forwardOutcome("pending")
+	forwardSucceeded = forwardOutcome("succeeded")
+	forwardQueued    = forwardOutcome("queued")
+	forwardFailed    = forwardOutcome("failed")
+)
+
+// checkFailure is a failed check of an advisory.
+type checkFailure struct {
+	Check  string `json:"check"`
+	Reason string `json:"reason"`
+}
+
+// advisoryReport is the outcome of a single advisory.
+type advisoryReport struct {
+	r *runReport
+
+	URL              string           `json:"url"`
+	Outcome          advisoryOutcome  `json:"outcome"`
+	Reason           string           `json:"reason,omitempty"`
+	Failures         []checkFailure   `json:"failures,omitempty"`
+	ValidationStatus validationStatus `json:"validation_status,omitempty"`
+	Path             string           `json:"path,omitempty"`
+	Forwarded        forwardOutcome   `json:"forwarded,omitempty"`
+}
+
+// domainReport is the outcome of a domain.
+type domainReport struct {
+	r *runReport
+
+	Domain           string                  `json:"domain"`
+	ProviderMetadata string                  `json:"provider_metadata,omitempty"`
+	Started          time.Time               `json:"started"`
+	Finished         time.Time               `json:"finished,omitzero"`
+	Error            string                  `json:"error,omitempty"`
+	Counts           map[advisoryOutcome]int `json:"counts"`
+	Advisories       []*advisoryReport       `json:"advisories"`
+}
+
+// runReport is the machine-readable report of a run.
+type runReport struct {
+	mu sync.Mutex
+
+	Started  time.Time       `json:"started"`
+	Finished time.Time       `json:"finished,omitzero"`
+	Domains  []*domainReport `json:"domains"`
+	Pruned   []*pruneReport  `json:"pruned,omitempty"`
+}
+
+// pruneReport is a pruned advisory.
+type pruneReport struct {
+	Path   string      `json:"path"`
+	Reason pruneReason `json:"reason"`
+	Action string      `json:"action"`
+}
+
+// newRunReport creates a new report if configured.
+func newRunReport(cfg *config) *runReport {
+	if cfg.Report == "" {
+		return nil
+	}
+	return &runReport{
+		Started: time.Now().UTC(),
+		Domains: []*domainReport{},
+	}
+}
+
+// domain adds the report of a domain.
+func (rr *runReport) domain(domain string) *domainReport {
+	if rr == nil {
+		return nil
+	}
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	dr := &domainReport{
+		r:          rr,
+		Domain:     domain,
+		Started:    time.Now().UTC(),
+		Counts:     map[advisoryOutcome]int{},
+		Advisories: []*advisoryReport{},
+	}
+	rr.Domains = append(rr.Domains, dr)
+	return dr
+}
+
+// providerMetadata records the URL of the used provider-metadata.json.
+func (dr *domainReport) providerMetadata(u string) {
+	if dr == nil {
+		return
+	}
+	dr.r.mu.Lock()
+	defer dr.r.mu.Unlock()
+	dr.ProviderMetadata = u
+}
+
+// finish records the end of the processing of the domain.
+func (dr *domainReport) finish(err error) {
+	if dr == nil {
+		return
+	}
+	dr.r.mu.Lock()
+	defer dr.r.mu.Unlock()
+	dr.Finished = time.Now().UTC()
+	if err != nil {
+		dr.Error = err.Error()
+	}
+}
+
+// advisory adds the report of an advisory.
+func (dr *domainReport) advisory(u string) *advisoryReport {
+	if dr == nil {
+		return nil
+	}
+	dr.r.mu.Lock()
+	defer dr.r.mu.Unlock()
+	ar := &advisoryReport{r: dr.r, URL: u}
+	dr.Advisories = append(dr.Advisories, ar)
+	return ar
+}
+
+// outcome records the result of processing the advisory.
+func (ar *advisoryReport) outcome(outcome advisoryOutcome, reason string) {
+	if ar == nil {
+		return
+	}
+	ar.r.mu.Lock()
+	defer ar.r.mu.Unlock()
+	ar.Outcome, ar.Reason = outcome, reason
+}
+
+// failed records a failed check. The advisory counts
+// as failed unless it is stored or forwarded anyway.
+func (ar *advisoryReport) failed(check string, err error) {
+	if ar == nil {
+		return
+	}
+	ar.r.mu.Lock()
+	defer ar.r.mu.Unlock()
+	ar.Outcome = outcomeFailed
+	ar.Failures = append(ar.Failures, checkFailure{Check: check, Reason: err.Error()})
+}
+
+// validated records the validation status of the advisory.
+func (ar *advisoryReport) validated(status validationStatus) {
+	if ar == nil {
+		return
+	}
+	ar.r.mu.Lock()
+	defer ar.r.mu.Unlock()
+	ar.ValidationStatus = status
+}
+
+// stored records the path the advisory is stored at.
+func (ar *advisoryReport) stored(path string) {
+	if ar == nil {
+		return
+	}
+	ar.r.mu.Lock()
+	defer ar.r.mu.Unlock()
+	ar.Outcome, ar.Path = outcomeDownloaded, path
+}
+
+// forwarded records the state of forwarding the advisory.
+func (ar *advisoryReport) forwarded(outcome forwardOutcome) {
+	if ar == nil {
+		return
+	}
+	ar.r.mu.Lock()
+	defer ar.r.mu.Unlock()
+	ar.Forwarded = outcome
+}
+
+// pruned records a pruned advisory.
+func (rr *runReport) pruned(path string, reason pruneReason, action string) {
+	if rr == nil {
+		return
+	}
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	rr.Pruned = append(rr.Pruned, &pruneReport{
+		Path:   path,
+		Reason: reason,
+		Action: action,
+	})
+}
+
+// empty returns true if no domain was processed.
+func (rr *runReport) empty() bool {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	return len(rr.Domains) == 0 && len(rr.Pruned) == 0
+}
+
+// write writes the report to the given file.
+func (rr *runReport) write(fname string) error {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	rr.Finished = time.Now().UTC()
+	for _, dr := range rr.Domains {
+		clear(dr.Counts)
+		for _, ar := range dr.Advisories {
+			dr.Counts[ar.Outcome]++
+		}
+	}
+	data, err := json.MarshalIndent(rr, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(fname), 0755); err != nil {
+		return err
+	}
+	return writeFileAtomic(fname, append(data, '\n'))
+}
+
+// writeReport writes the report of the run if configured.
+// Empty reports are not written to not overwrite the report
+// of the last download in daemon mode.
+func (d *downloader) writeReport() {
+	if d.report == nil || d.report.empty() {
+		return
+	}
+	fname := d.cfg.reportFile()
+	if err := d.report.write(fname); err != nil {
+		slog.Error("Writing run report failed",
+			"file", fname,
+			"error", err)
+		return
+	}
+	slog.Info("Run report written", "file", fname)
+}
@@ -0,0 +1,130 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gocsaf/csaf/v3/internal/options"
+	"github.com/gocsaf/csaf/v3/internal/testutil"
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+func TestRunReport(t *testing.T) {
+	params := testutil.ProviderParams{
+		EnableSha256: true,
+		EnableSha512: true,
+	}
+	server := httptest.NewTLSServer(testutil.ProviderHandler(&params, false))
+	defer server.Close()
+	params.URL = server.URL
+
+	client := util.Client(server.Client())
+
+	for _, tc := range []struct {
+		name    string
+		cves    []string
+		outcome advisoryOutcome
+		reason  string
+	}{
+		{name: "downloaded", outcome: outcomeDownloaded},
+		{name: "filtered", cves: []string{"CVE-1999-0001"}, outcome: outcomeFiltered, reason: "cve"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			cfg := config{
+				LogLevel:   &options.LogLevel{Level: slog.LevelError},
+				Directory:  dir,
+				Report:     "report.json",
+				FilterCVEs: tc.cves,
+			}
+			if err := cfg.prepare(); err != nil {
+				t.Fatal(err)
+			}
+			d, err := newDownloader(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			d.client = &client
+			err = d.run(context.Background(), []string{server.URL + "/provider-metadata.json"})
+			d.writeReport()
+			d.close()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(filepath.Join(dir, "report.json"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var report runReport
+			if err := json.Unmarshal(data, &report); err != nil {
+				t.Fatal(err)
+			}
+			if len(report.Domains) != 1 {
+				t.Fatalf("domains: got %d, want 1", len(report.Domains))
+			}
+			dr := report.Domains[0]
+			if dr.Error != "" {
+				t.Errorf("error: got %q, want none", dr.Error)
+			}
+			if !strings.HasSuffix(dr.ProviderMetadata, "/provider-metadata.json") {
+				t.Errorf("provider metadata: got %q", dr.ProviderMetadata)
+			}
+			if len(dr.Advisories) == 0 {
+				t.Fatal("no advisories reported")
+			}
+			if n := dr.Counts[tc.outcome]; n != len(dr.Advisories) {
+				t.Errorf("count of %s: got %d, want %d", tc.outcome, n, len(dr.Advisories))
+			}
+			for _, ar := range dr.Advisories {
+				if ar.Outcome != tc.outcome {
+					t.Errorf("%s: outcome: got %q, want %q", ar.URL, ar.Outcome, tc.outcome)
+				}
+				if ar.Reason != tc.reason {
+					t.Errorf("%s: reason: got %q, want %q", ar.URL, ar.Reason, tc.reason)
+				}
+				if ar.ValidationStatus != validValidationStatus {
+					t.Errorf("%s: validation status: got %q", ar.URL, ar.ValidationStatus)
+				}
+				if stored := ar.Path != ""; stored != (tc.outcome == outcomeDownloaded) {
+					t.Errorf("%s: unexpected path %q", ar.URL, ar.Path)
+				}
+			}
+		})
+	}
+}
+
+func TestRunReportFailures(t *testing.T) {
+	rr := &runReport{}
+	ar := rr.domain("example.com").advisory("https://example.com/a.json")
+	ar.failed("sha256", os.ErrInvalid)
+	ar.forwarded(forwardQueued)
+
+	// A nil report ignores all records.
+	var none *runReport
+	none.domain("example.com").advisory("x").failed("schema", os.ErrInvalid)
+
+	if ar.Outcome != outcomeFailed {
+		t.Errorf("outcome: got %q, want %q", ar.Outcome, outcomeFailed)
+	}
+	if len(ar.Failures) != 1 || ar.Failures[0].Check != "sha256" {
+		t.Errorf("failures: got %v", ar.Failures)
+	}
+	if ar.Forwarded != forwardQueued {
+		t.Errorf("forwarded: got %q, want %q", ar.Forwarded, forwardQueued)
+	}
+}
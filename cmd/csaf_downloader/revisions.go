@@ -0,0 +1,144 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gocsaf/csaf/v3/internal/misc"
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+// revisionsDir is the name of the sub folder next to the
+// stored advisories where superseded revisions are archived.
+const revisionsDir = "revisions"
+
+// revisionsIndexFile is the name of the file recording
+// the current and the archived revisions of an advisory.
+const revisionsIndexFile = "revisions.json"
+
+// archivedRevision is a superseded revision of an advisory.
+type archivedRevision struct {
+	Version            string    `json:"version"`
+	CurrentReleaseDate string    `json:"current_release_date,omitempty"`
+	Folder             string    `json:"folder"`
+	Archived           time.Time `json:"archived"`
+}
+
+// revisions records the revisions of an advisory.
+type revisions struct {
+	Current            string              `json:"current"`
+	CurrentReleaseDate string              `json:"current_release_date,omitempty"`
+	Updated            time.Time           `json:"updated"`
+	Archived           []*archivedRevision `json:"archived"`
+}
+
+// revisionInfo extracts version and current release date from an advisory.
+// Missing values are returned as empty strings.
+func revisionInfo(expr *util.PathEval, doc any) (version, released string) {
+	expr.Match([]util.PathEvalMatcher{
+		{Expr: `$.document.tracking.version`, Action: util.StringMatcher(&version), Optional: true},
+		{Expr: `$.document.tracking.current_release_date`, Action: util.StringMatcher(&released), Optional: true},
+	}, doc)
+	return
+}
+
+// archiveRevision moves the advisory stored at path and its
+// hash and signature files to the revisions folder if the
+// given data differs from it. The revisions.json of the
+// advisory is updated to record doc as the current revision.
+func (dc *downloadContext) archiveRevision(path string, data []byte, doc any) error {
+	old, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if bytes.Equal(old, data) {
+		return nil
+	}
+
+	var oldDoc any
+	if err := misc.StrictJSONParse(bytes.NewReader(old), &oldDoc); err != nil {
+		return fmt.Errorf("parsing stored revision failed: %w", err)
+	}
+	oldVersion, oldReleased := revisionInfo(dc.expr, oldDoc)
+	newVersion, newReleased := revisionInfo(dc.expr, doc)
+	if oldVersion == "" {
+		oldVersion = "unknown"
+	}
+
+	filename := filepath.Base(path)
+	base := filepath.Join(
+		filepath.Dir(path), revisionsDir, strings.TrimSuffix(filename, ".json"))
+
+	revs := new(revisions)
+	index := filepath.Join(base, revisionsIndexFile)
+	if data, err := os.ReadFile(index); err == nil {
+		if err := json.Unmarshal(data, revs); err != nil {
+			return fmt.Errorf("parsing %s failed: %w", index, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	// Versions may be republished without being increased.
+	folder := oldVersion
+	for n := 1; ; n++ {
+		if _, err := os.Stat(filepath.Join(base, folder)); errors.Is(err, os.ErrNotExist) {
+			break
+		} else if err != nil {
+			return err
+		}
+		folder = fmt.Sprintf("%s_%d", oldVersion, n)
+	}
+	dir := filepath.Join(base, folder)
+	if err := dc.d.mkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, ext := range []string{"", ".sha256", ".sha512", ".asc"} {
+		if err := os.Rename(path+ext, filepath.Join(dir, filename+ext)); err != nil &&
+			!errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	now := time.Now().UTC()
+	revs.Archived = append(revs.Archived, &archivedRevision{
+		Version:            oldVersion,
+		CurrentReleaseDate: oldReleased,
+		Folder:             folder,
+		Archived:           now,
+	})
+	revs.Current, revs.CurrentReleaseDate, revs.Updated = newVersion, newReleased, now
+
+	out, err := json.MarshalIndent(revs, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(index, append(out, '\n')); err != nil {
+		return err
+	}
+
+	slog.Info("Advisory revision superseded",
+		"filename", filename,
+		"old_version", oldVersion,
+		"new_version", newVersion,
+		"archived", dir)
+	return nil
+}
@@ -0,0 +1,110 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+func TestArchiveRevision(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "avendor-advisory-0004.json")
+
+	dc := &downloadContext{
+		d:    &downloader{cfg: &config{Directory: dir}},
+		expr: util.NewPathEval(),
+	}
+
+	revision := func(version string) ([]byte, any) {
+		data := fmt.Appendf(nil,
+			`{"document":{"tracking":{"version":%q,"current_release_date":"2026-01-0%sT00:00:00Z"}}}`,
+			version, version)
+		var doc any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			t.Fatal(err)
+		}
+		return data, doc
+	}
+
+	// store simulates the downloader writing a new revision.
+	store := func(version string) {
+		data, doc := revision(version)
+		if err := dc.archiveRevision(path, data, doc); err != nil {
+			t.Fatal(err)
+		}
+		for _, x := range []struct {
+			p string
+			d []byte
+		}{
+			{path, data},
+			{path + ".sha256", []byte(version)},
+		} {
+			if err := os.WriteFile(x.p, x.d, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	store("1")
+	store("1") // Unchanged, nothing to archive.
+	store("2")
+	store("3")
+
+	base := filepath.Join(dir, revisionsDir, "avendor-advisory-0004")
+	for _, version := range []string{"1", "2"} {
+		for _, name := range []string{"avendor-advisory-0004.json", "avendor-advisory-0004.json.sha256"} {
+			if _, err := os.Stat(filepath.Join(base, version, name)); err != nil {
+				t.Errorf("revision %s: %v", version, err)
+			}
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(base, revisionsIndexFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var revs revisions
+	if err := json.Unmarshal(data, &revs); err != nil {
+		t.Fatal(err)
+	}
+	if revs.Current != "3" {
+		t.Errorf("current: got %q, want %q", revs.Current, "3")
+	}
+	if len(revs.Archived) != 2 ||
+		revs.Archived[0].Version != "1" || revs.Archived[1].Version != "2" {
+		t.Errorf("unexpected archived revisions: %s", data)
+	}
+
+	// A changed advisory republished under the same version
+	// must not overwrite the archived one.
+	data, doc := revision("3")
+	data = append(data, ' ')
+	if err := dc.archiveRevision(path, data, doc); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(base, "3", "avendor-advisory-0004.json")); err != nil {
+		t.Error(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	data, doc = revision("3")
+	if err := dc.archiveRevision(path, data, doc); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(base, "3_1", "avendor-advisory-0004.json")); err != nil {
+		t.Error(err)
+	}
+}
@@ -20,6 +20,8 @@ type stats struct {
 	sha512Failed    int
 	signatureFailed int
 	succeeded       int
+	notModified     int
+	filtered        int
 }
 
 // add adds other stats to this.
@@ -32,6 +34,8 @@ func (st *stats) add(o *stats) {
 	st.sha512Failed += o.sha512Failed
 	st.signatureFailed += o.signatureFailed
 	st.succeeded += o.succeeded
+	st.notModified += o.notModified
+	st.filtered += o.filtered
 }
 
 func (st *stats) totalFailed() int {
@@ -48,6 +52,8 @@ func (st *stats) totalFailed() int {
 func (st *stats) log() {
 	slog.Info("Download statistics",
 		"succeeded", st.succeeded,
+		"not_modified", st.notModified,
+		"filtered", st.filtered,
 		"total_failed", st.totalFailed(),
 		"filename_failed", st.filenameFailed,
 		"download_failed", st.downloadFailed,
@@ -0,0 +1,322 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/gocsaf/csaf/v3/csaf"
+)
+
+var (
+	advisoriesBucket = []byte("advisories")
+	providersBucket  = []byte("providers")
+	syncVersionKey   = []byte("version")
+	syncVersion      = []byte("1")
+)
+
+// syncDeltaOverlap is subtracted from the time of the last
+// synchronization when filtering changes.csv and ROLIE feed entries.
+// This compensates providers which publish advisories with
+// a release date slightly in the past.
+const syncDeltaOverlap = 24 * time.Hour
+
+// syncEntry is the state of a downloaded advisory.
+type syncEntry struct {
+	ETag               string    `json:"etag,omitempty"`
+	LastModified       string    `json:"last_modified,omitempty"`
+	SHA256             string    `json:"sha256,omitempty"`
+	SHA512             string    `json:"sha512,omitempty"`
+	Revision           string    `json:"revision,omitempty"`
+	CurrentReleaseDate time.Time `json:"current_release_date,omitzero"`
+	Path               string    `json:"path,omitempty"`
+	Synced             time.Time `json:"synced"`
+}
+
+// hasValidators returns true if the entry allows conditional requests.
+func (se *syncEntry) hasValidators() bool {
+	return se.ETag != "" || se.LastModified != ""
+}
+
+// conditional adds the validators of the entry to the given request.
+func (se *syncEntry) conditional(req *http.Request) {
+	if se.ETag != "" {
+		req.Header.Set("If-None-Match", se.ETag)
+	}
+	if se.LastModified != "" {
+		req.Header.Set("If-Modified-Since", se.LastModified)
+	}
+}
+
+// syncState is the persistent state of incremental downloads.
+type syncState struct {
+	db *bolt.DB
+}
+
+// openSyncState opens the state stored in the given file.
+// The buckets are re-created if they are of an other version.
+func openSyncState(fname string) (*syncState, error) {
+	db, err := bolt.Open(fname, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{advisoriesBucket, providersBucket} {
+			create := func() error {
+				b, err := tx.CreateBucket(name)
+				if err != nil {
+					return err
+				}
+				return b.Put(syncVersionKey, syncVersion)
+			}
+			b := tx.Bucket(name)
+			if b == nil {
+				if err := create(); err != nil {
+					return err
+				}
+				continue
+			}
+			if v := b.Get(syncVersionKey); !bytes.Equal(v, syncVersion) {
+				if err := tx.DeleteBucket(name); err != nil {
+					return err
+				}
+				if err := create(); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &syncState{db: db}, nil
+}
+
+// close closes the underlying database.
+func (ss *syncState) close() error {
+	return ss.db.Close()
+}
+
+// get returns the stored state of the advisory with the given URL.
+// It returns nil if there is none.
+func (ss *syncState) get(u string) (*syncEntry, error) {
+	var entry *syncEntry
+	if err := ss.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(advisoriesBucket).Get([]byte(u))
+		if data == nil {
+			return nil
+		}
+		entry = new(syncEntry)
+		return json.Unmarshal(data, entry)
+	}); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// put stores the state of the advisory with the given URL.
+func (ss *syncState) put(u string, entry *syncEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return ss.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(advisoriesBucket).Put([]byte(u), data)
+	})
+}
+
+// pruned updates the entries of the advisories stored at the given path.
+// Withdrawn advisories keep their entries without the path to not be
+// downloaded again as long as they are unchanged. The entries of
+// vanished advisories are removed.
+func (ss *syncState) pruned(path string, reason pruneReason) error {
+	return ss.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(advisoriesBucket)
+		updates := map[string]*syncEntry{}
+		if err := b.ForEach(func(k, v []byte) error {
+			if bytes.Equal(k, syncVersionKey) {
+				return nil
+			}
+			var entry syncEntry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return err
+			}
+			if entry.Path == path {
+				entry.Path = ""
+				updates[string(k)] = &entry
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		for u, entry := range updates {
+			if reason != pruneWithdrawn {
+				if err := b.Delete([]byte(u)); err != nil {
+					return err
+				}
+				continue
+			}
+			data, err := json.Marshal(entry)
+			if err != nil {
+				return err
+			}
+			if err := b.Put([]byte(u), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// lastSync returns the time of the last complete
+// synchronization of the provider with the given metadata URL.
+func (ss *syncState) lastSync(pmdURL string) (time.Time, error) {
+	var last time.Time
+	if err := ss.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(providersBucket).Get([]byte(pmdURL))
+		if data == nil {
+			return nil
+		}
+		return last.UnmarshalText(data)
+	}); err != nil {
+		return time.Time{}, err
+	}
+	return last, nil
+}
+
+// setLastSync stores the time of the last complete
+// synchronization of the provider with the given metadata URL.
+func (ss *syncState) setLastSync(pmdURL string, t time.Time) error {
+	data, err := t.UTC().MarshalText()
+	if err != nil {
+		return err
+	}
+	return ss.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(providersBucket).Put([]byte(pmdURL), data)
+	})
+}
+
+// previous returns the stored entry of the advisory with the given
+// URL if there is one and its local copy still exists.
+func (ss *syncState) previous(u string) (*syncEntry, error) {
+	entry, err := ss.get(u)
+	if err != nil || entry == nil {
+		return nil, err
+	}
+	if entry.Path != "" {
+		if _, err := os.Stat(entry.Path); err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+	}
+	return entry, nil
+}
+
+// previous returns the stored state of the given advisory
+// if incremental downloads are enabled.
+func (dc *downloadContext) previous(file csaf.AdvisoryFile) *syncEntry {
+	if dc.d.state == nil || dc.d.cfg.FullSync {
+		return nil
+	}
+	prev, err := dc.d.state.previous(file.URL())
+	if err != nil {
+		slog.Warn("Cannot load synchronization state",
+			"url", file.URL(),
+			"error", err)
+		return nil
+	}
+	return prev
+}
+
+// unchangedHash checks if the remote hash of the given advisory
+// matches the one stored in the synchronization state.
+// This is used for servers not supporting conditional requests.
+func (dc *downloadContext) unchangedHash(
+	ctx context.Context,
+	file csaf.AdvisoryFile,
+	prev *syncEntry,
+) bool {
+	var hashURL, stored string
+	switch {
+	case prev.SHA512 != "" && file.SHA512URL() != "":
+		hashURL, stored = file.SHA512URL(), prev.SHA512
+	case prev.SHA256 != "" && file.SHA256URL() != "":
+		hashURL, stored = file.SHA256URL(), prev.SHA256
+	default:
+		return false
+	}
+	remote, _, err := loadHash(ctx, dc.client, hashURL)
+	if err != nil {
+		slog.Debug("Cannot fetch hash to detect changes",
+			"url", hashURL,
+			"error", err)
+		return false
+	}
+	return hex.EncodeToString(remote) == stored
+}
+
+// recordSync stores the state of a successfully downloaded advisory.
+func (dc *downloadContext) recordSync(
+	file csaf.AdvisoryFile,
+	resp *http.Response,
+	data []byte,
+	doc any,
+	path string,
+) {
+	if dc.d.state == nil {
+		return
+	}
+	s256 := sha256.Sum256(data)
+	s512 := sha512.Sum512(data)
+	entry := &syncEntry{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		SHA256:       hex.EncodeToString(s256[:]),
+		SHA512:       hex.EncodeToString(s512[:]),
+		Path:         path,
+		Synced:       time.Now().UTC(),
+	}
+	if v, err := dc.expr.Eval(`$.document.tracking.version`, doc); err == nil {
+		entry.Revision, _ = v.(string)
+	}
+	if v, err := dc.expr.Eval(`$.document.tracking.current_release_date`, doc); err == nil {
+		if text, ok := v.(string); ok {
+			entry.CurrentReleaseDate, _ = time.Parse(time.RFC3339, text)
+		}
+	}
+
+	if old, err := dc.d.state.get(file.URL()); err == nil && old != nil &&
+		old.Revision != entry.Revision {
+		slog.Info("New revision of advisory",
+			"url", file.URL(),
+			"revision", entry.Revision,
+			"previous", old.Revision)
+	}
+
+	if err := dc.d.state.put(file.URL(), entry); err != nil {
+		slog.Error("Storing synchronization state failed",
+			"url", file.URL(),
+			"error", err)
+	}
+}
@@ -0,0 +1,254 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gocsaf/csaf/v3/internal/options"
+	"github.com/gocsaf/csaf/v3/internal/testutil"
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+func TestIncrementalSync(t *testing.T) {
+	const etag = `"v1"`
+
+	for _, test := range []struct {
+		name string
+		etag bool
+	}{
+		{name: "conditional requests", etag: true},
+		{name: "hashes"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			params := testutil.ProviderParams{
+				EnableSha256: true,
+				EnableSha512: true,
+			}
+			provider := testutil.ProviderHandler(&params, false)
+			var advisoryGets int
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasSuffix(r.URL.Path, ".json") && strings.Contains(r.URL.Path, "advisory") {
+					advisoryGets++
+					if test.etag {
+						if r.Header.Get("If-None-Match") == etag {
+							w.WriteHeader(http.StatusNotModified)
+							return
+						}
+						w.Header().Set("ETag", etag)
+					}
+				}
+				provider(w, r)
+			}))
+			defer server.Close()
+			params.URL = server.URL
+
+			client := util.Client(server.Client())
+			dir := t.TempDir()
+
+			sync := func() stats {
+				cfg := config{
+					LogLevel:  &options.LogLevel{Level: slog.LevelError},
+					Directory: dir,
+					SyncState: "sync.db",
+				}
+				if err := cfg.prepare(); err != nil {
+					t.Fatal(err)
+				}
+				d, err := newDownloader(&cfg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer d.close()
+				d.client = &client
+				if err := d.run(context.Background(),
+					[]string{server.URL + "/provider-metadata.json"}); err != nil {
+					t.Fatal(err)
+				}
+				return d.stats
+			}
+
+			resetLastSync := func() {
+				state, err := openSyncState(dir + "/sync.db")
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer state.close()
+				if err := state.setLastSync(server.URL+"/provider-metadata.json", time.Time{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if st := sync(); st.succeeded != 1 || advisoryGets != 1 {
+				t.Fatalf("first run: succeeded %d, requests %d", st.succeeded, advisoryGets)
+			}
+
+			// The advisory is older than the last synchronization.
+			if st := sync(); st.succeeded != 0 || st.notModified != 0 || advisoryGets != 1 {
+				t.Fatalf("delta run: succeeded %d, not modified %d, requests %d",
+					st.succeeded, st.notModified, advisoryGets)
+			}
+
+			resetLastSync()
+
+			wantGets := 1
+			if test.etag {
+				wantGets = 2
+			}
+			if st := sync(); st.succeeded != 0 || st.notModified != 1 || advisoryGets != wantGets {
+				t.Fatalf("unchanged run: succeeded %d, not modified %d, requests %d",
+					st.succeeded, st.notModified, advisoryGets)
+			}
+		})
+	}
+}
+
+func TestIncrementalSyncFailedValidation(t *testing.T) {
+	const etag = `"v1"`
+
+	params := testutil.ProviderParams{
+		EnableSha256: true,
+		EnableSha512: true,
+	}
+	provider := testutil.ProviderHandler(&params, false)
+	var conditionalGets int
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, ".sha256"):
+			// Let the advisory fail the validation.
+			w.Write([]byte("0000  broken.json\n"))
+			return
+		case strings.HasSuffix(r.URL.Path, ".json") && strings.Contains(r.URL.Path, "advisory"):
+			if r.Header.Get("If-None-Match") != "" {
+				conditionalGets++
+			}
+			w.Header().Set("ETag", etag)
+		}
+		provider(w, r)
+	}))
+	defer server.Close()
+	params.URL = server.URL
+
+	client := util.Client(server.Client())
+	dir := t.TempDir()
+	pmdURL := server.URL + "/provider-metadata.json"
+
+	for run := range 2 {
+		cfg := config{
+			LogLevel:       &options.LogLevel{Level: slog.LevelError + 1},
+			Directory:      dir,
+			SyncState:      "sync.db",
+			ValidationMode: validationUnsafe,
+		}
+		if err := cfg.prepare(); err != nil {
+			t.Fatal(err)
+		}
+		d, err := newDownloader(&cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.client = &client
+		if err := d.run(context.Background(), []string{pmdURL}); err != nil {
+			t.Fatal(err)
+		}
+		d.close()
+		if d.stats.succeeded != 1 {
+			t.Fatalf("run %d: succeeded %d", run, d.stats.succeeded)
+		}
+
+		state, err := openSyncState(dir + "/sync.db")
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry, err := state.get(server.URL + "/white/avendor-advisory-0004.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Force the next run to look at the advisory again.
+		if err := state.setLastSync(pmdURL, time.Time{}); err != nil {
+			t.Fatal(err)
+		}
+		state.close()
+		if entry != nil {
+			t.Fatalf("run %d: advisory which failed validation was recorded", run)
+		}
+	}
+	if conditionalGets != 0 {
+		t.Errorf("advisory which failed validation was requested conditionally %d times", conditionalGets)
+	}
+}
+
+func TestIncrementalSyncFiltered(t *testing.T) {
+	params := testutil.ProviderParams{
+		EnableSha256: true,
+		EnableSha512: true,
+	}
+	provider := testutil.ProviderHandler(&params, false)
+	var advisoryGets int
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".json") && strings.Contains(r.URL.Path, "advisory") {
+			advisoryGets++
+		}
+		provider(w, r)
+	}))
+	defer server.Close()
+	params.URL = server.URL
+
+	client := util.Client(server.Client())
+	dir := t.TempDir()
+	pmdURL := server.URL + "/provider-metadata.json"
+
+	for run := range 2 {
+		cfg := config{
+			LogLevel:         &options.LogLevel{Level: slog.LevelError},
+			Directory:        dir,
+			SyncState:        "sync.db",
+			FilterCategories: []string{"csaf_security_advisory"},
+		}
+		if err := cfg.prepare(); err != nil {
+			t.Fatal(err)
+		}
+		d, err := newDownloader(&cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.client = &client
+		if err := d.run(context.Background(), []string{pmdURL}); err != nil {
+			t.Fatal(err)
+		}
+		d.close()
+
+		if run == 0 && d.stats.filtered != 1 {
+			t.Fatalf("first run: filtered %d", d.stats.filtered)
+		}
+		if run == 1 && (d.stats.filtered != 0 || d.stats.notModified != 1) {
+			t.Fatalf("second run: filtered %d, not modified %d",
+				d.stats.filtered, d.stats.notModified)
+		}
+
+		// Force the next run to look at the advisory again.
+		state, err := openSyncState(dir + "/sync.db")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := state.setLastSync(pmdURL, time.Time{}); err != nil {
+			t.Fatal(err)
+		}
+		state.close()
+	}
+	if advisoryGets != 1 {
+		t.Errorf("filtered advisory was fetched %d times", advisoryGets)
+	}
+}
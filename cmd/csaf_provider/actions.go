@@ -14,7 +14,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -91,7 +91,7 @@ func (c *controller) handleSignature(
 			crypto.NewPlainMessage(data),
 			pgpSig, crypto.GetUnixTime(),
 		); err != nil {
-			return "", nil, err
+			return "", nil, fmt.Errorf("%w: %w", errInvalidSignature, err)
 		}
 
 		return sigText, key, nil
@@ -185,7 +185,7 @@ func (c *controller) upload(r *http.Request) (any, error) {
 			return nil, err
 		}
 		if !rvr.Valid {
-			return nil, errors.New("does not validate against remote validator")
+			return nil, errRemoteValidation
 		}
 	}
 
@@ -210,8 +210,9 @@ func (c *controller) upload(r *http.Request) (any, error) {
 		for _, expr := range catExprs {
 			// Compile first to check that the expression is okay.
 			if _, err := pe.Compile(expr); err != nil {
-				log.Printf("Compiling category expression %q failed: %v\n",
-					expr, err)
+				slog.Error("Compiling category expression failed",
+					"expr", expr,
+					"error", err)
 				continue
 			}
 			// Ignore errors here as they result from not matching.
@@ -310,6 +311,8 @@ func (c *controller) upload(r *http.Request) (any, error) {
 		return nil, err
 	}
 
+	slog.Info("Advisory uploaded", "advisory", ex.ID, "filename", newCSAF)
+
 	result := struct {
 		Name        string   `json:"name"`
 		ReleaseDate string   `json:"release_date"`
@@ -11,6 +11,7 @@ package main
 import (
 	"fmt"
 	"io"
+	"log/slog"
 	"net/url"
 	"os"
 	"strings"
@@ -20,6 +21,7 @@ import (
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/gocsaf/csaf/v3/csaf"
+	"github.com/gocsaf/csaf/v3/internal/options"
 )
 
 const (
@@ -65,6 +67,10 @@ type config struct {
 	ServiceDocument         bool                         `toml:"create_service_document"`
 	WriteIndices            bool                         `toml:"write_indices"`
 	WriteSecurity           bool                         `toml:"write_security"`
+	MetricsFile             string                       `toml:"metrics_file"`
+	LogFile                 string                       `toml:"log_file"`
+	LogLevel                *options.LogLevel            `toml:"log_level"`
+	LogFormat               options.LogFormat            `toml:"log_format"`
 }
 
 func (pmdc *providerMetadataConfig) apply(pmd *csaf.ProviderMetadata) {
@@ -295,3 +301,16 @@ func loadConfig() (*config, error) {
 
 	return &cfg, nil
 }
+
+// prepareLogging sets up the structured logging.
+func (cfg *config) prepareLogging() error {
+	lg := options.Logging{
+		Format: cfg.LogFormat,
+		Level:  slog.LevelInfo,
+		File:   cfg.LogFile,
+	}
+	if cfg.LogLevel != nil {
+		lg.Level = cfg.LogLevel.Level
+	}
+	return lg.SetDefault()
+}
@@ -12,7 +12,7 @@ import (
 	"embed"
 	"encoding/json"
 	"html/template"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"strings"
@@ -63,12 +63,17 @@ func newController(cfg *config) (*controller, error) {
 // bind binds the paths with the corresponding http.handler and wraps it with the respective middleware,
 // according to the "NoWebUI" config value.
 func (c *controller) bind(pim *pathInfoMux) {
+	upload := c.upload
+	if c.cfg.MetricsFile != "" {
+		upload = c.counted(upload)
+		pim.handleFunc("/metrics", c.auth(c.metrics))
+	}
 	if !c.cfg.NoWebUI {
 		pim.handleFunc("/", c.auth(c.index))
-		pim.handleFunc("/upload", c.auth(c.web(c.upload, "upload.html")))
+		pim.handleFunc("/upload", c.auth(c.web(upload, "upload.html")))
 		pim.handleFunc("/create", c.auth(c.web(c.create, "create.html")))
 	}
-	pim.handleFunc("/api/upload", c.auth(api(c.upload)))
+	pim.handleFunc("/api/upload", c.auth(api(upload)))
 	pim.handleFunc("/api/create", c.auth(api(c.create)))
 }
 
@@ -77,13 +82,13 @@ func (c *controller) bind(pim *pathInfoMux) {
 func (c *controller) authenticate(r *http.Request) bool {
 
 	verify := os.Getenv("SSL_CLIENT_VERIFY")
-	log.Printf("SSL_CLIENT_VERIFY: %s\n", verify)
+	slog.Info("Client certificate", "verify", verify)
 	if verify == "SUCCESS" || strings.HasPrefix(verify, "FAILED") {
 		// potentially we want to see the Issuer when there is a problem
 		// but it is not clear if we get this far in case of "FAILED".
 		// docs (accessed 2022-03-31 when 1.20.2 was current stable):
 		// https://nginx.org/en/docs/http/ngx_http_ssl_module.html#var_ssl_client_verify
-		log.Printf("SSL_CLIENT_I_DN: %s\n", os.Getenv("SSL_CLIENT_I_DN"))
+		slog.Info("Client certificate", "issuer", os.Getenv("SSL_CLIENT_I_DN"))
 	}
 
 	checkCert := func() bool {
@@ -96,18 +101,18 @@ func (c *controller) authenticate(r *http.Request) bool {
 
 	if c.cfg.CertificateAndPassword {
 		if c.cfg.Password == nil {
-			log.Println("No password set, declining access.")
+			slog.Warn("No password set, declining access.")
 			return false
 		}
-		log.Printf("user: %s\n", os.Getenv("SSL_CLIENT_S_DN"))
+		slog.Info("Client certificate", "user", os.Getenv("SSL_CLIENT_S_DN"))
 		return checkPassword() && checkCert()
 	}
 
 	switch {
 	case checkCert():
-		log.Printf("user: %s\n", os.Getenv("SSL_CLIENT_S_DN"))
+		slog.Info("Client certificate", "user", os.Getenv("SSL_CLIENT_S_DN"))
 	case c.cfg.Password == nil:
-		log.Println("No password set, declining access.")
+		slog.Warn("No password set, declining access.")
 		return false
 	default:
 		return checkPassword()
@@ -135,7 +140,7 @@ func (c *controller) render(rw http.ResponseWriter, tmpl string, arg any) {
 	rw.Header().Set("Content-type", "text/html; charset=utf-8")
 	rw.Header().Set("X-Content-Type-Options", "nosniff")
 	if err := c.tmpl.ExecuteTemplate(rw, tmpl, arg); err != nil {
-		log.Printf("warn: %v\n", err)
+		slog.Warn("Rendering template failed", "template", tmpl, "error", err)
 	}
 }
 
@@ -178,7 +183,7 @@ func writeJSON(rw http.ResponseWriter, content any, code int) {
 	rw.Header().Set("X-Content-Type-Options", "nosniff")
 	rw.WriteHeader(code)
 	if err := json.NewEncoder(rw).Encode(content); err != nil {
-		log.Printf("error: %v\n", err)
+		slog.Error("Writing JSON failed", "error", err)
 	}
 }
 
@@ -11,17 +11,17 @@ package main
 
 import (
 	"fmt"
-	"log"
 	"net/http"
 	"net/http/cgi"
 	"os"
 
 	"github.com/jessevdk/go-flags"
 
+	"github.com/gocsaf/csaf/v3/internal/options"
 	"github.com/gocsaf/csaf/v3/util"
 )
 
-type options struct {
+type cliOptions struct {
 	Version bool `long:"version" description:"Display version of the binary"`
 }
 
@@ -36,7 +36,7 @@ func ensureCGI() {
 }
 
 func main() {
-	var opts options
+	var opts cliOptions
 	parser := flags.NewParser(&opts, flags.Default)
 	parser.Parse()
 	if opts.Version {
@@ -52,17 +52,15 @@ func main() {
 			http.Error(rw, "Something went wrong. Check server logs for more details",
 				http.StatusInternalServerError)
 		}))
-		log.Fatalf("error: %v\n", err)
+		options.ErrorCheckStructured(err)
 	}
 
+	options.ErrorCheckStructured(cfg.prepareLogging())
+
 	c, err := newController(cfg)
-	if err != nil {
-		log.Fatalf("error: %v\n", err)
-	}
+	options.ErrorCheckStructured(err)
 	pim := newPathInfoMux()
 	c.bind(pim)
 
-	if err := cgi.Serve(pim); err != nil {
-		log.Fatalf("error: %v\n", err)
-	}
+	options.ErrorCheckStructured(cgi.Serve(pim))
 }
@@ -0,0 +1,148 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gofrs/flock"
+
+	"github.com/gocsaf/csaf/v3/internal/metrics"
+)
+
+var (
+	// errRemoteValidation is returned if an uploaded
+	// advisory does not pass the remote validator.
+	errRemoteValidation = errors.New("does not validate against remote validator")
+	// errInvalidSignature is returned if the signature
+	// of an uploaded advisory does not verify.
+	errInvalidSignature = errors.New("invalid signature")
+)
+
+// uploadCounts are the counters of the uploads. As the provider
+// runs as CGI they are kept in a file between the requests.
+type uploadCounts struct {
+	Processed map[string]float64 `json:"processed"`
+	Failed    map[string]float64 `json:"failed"`
+}
+
+// failureReason returns the reason an upload failed for.
+func failureReason(err error) string {
+	var me multiError
+	switch {
+	case errors.As(err, &me):
+		return "schema"
+	case errors.Is(err, errRemoteValidation):
+		return "remote"
+	case errors.Is(err, errInvalidSignature):
+		return "signature"
+	}
+	return "other"
+}
+
+// loadUploadCounts loads the counters from file.
+// A missing file results in empty counters.
+func loadUploadCounts(fname string) (*uploadCounts, error) {
+	counts := uploadCounts{
+		Processed: map[string]float64{},
+		Failed:    map[string]float64{},
+	}
+	f, err := os.Open(fname)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &counts, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(&counts); err != nil {
+		return nil, err
+	}
+	return &counts, nil
+}
+
+// save writes the counters atomically to file.
+func (uc *uploadCounts) save(fname string) error {
+	f, err := os.CreateTemp(filepath.Dir(fname), "."+filepath.Base(fname)+"-*")
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(uc); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), fname)
+}
+
+// countUpload counts the outcome of an upload in the metrics file.
+func countUpload(fname string, uploadErr error) error {
+	fl := flock.New(fname + ".lock")
+	if err := fl.Lock(); err != nil {
+		return err
+	}
+	defer fl.Unlock()
+
+	counts, err := loadUploadCounts(fname)
+	if err != nil {
+		return err
+	}
+	if uploadErr != nil {
+		counts.Processed["failed"]++
+		counts.Failed[failureReason(uploadErr)]++
+	} else {
+		counts.Processed["succeeded"]++
+	}
+	return counts.save(fname)
+}
+
+// counted counts the outcomes of the uploads handled by fn.
+func (c *controller) counted(
+	fn func(*http.Request) (any, error),
+) func(*http.Request) (any, error) {
+	return func(r *http.Request) (any, error) {
+		content, err := fn(r)
+		// The web UI shows the upload form on GET.
+		if r.Method == http.MethodPost {
+			if err := countUpload(c.cfg.MetricsFile, err); err != nil {
+				slog.Error("Counting upload failed", "error", err)
+			}
+		}
+		return content, err
+	}
+}
+
+// metrics serves the counters of the uploads in the Prometheus text format.
+func (c *controller) metrics(rw http.ResponseWriter, r *http.Request) {
+	counts, err := loadUploadCounts(c.cfg.MetricsFile)
+	if err != nil {
+		slog.Error("Loading metrics failed", "error", err)
+		http.Error(rw, http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError)
+		return
+	}
+	reg := metrics.NewRegistry()
+	processed, failed := reg.Processed(), reg.Failed()
+	for result, n := range counts.Processed {
+		processed.Add(n, result)
+	}
+	for reason, n := range counts.Failed {
+		failed.Add(n, reason)
+	}
+	reg.ServeHTTP(rw, r)
+}
@@ -12,6 +12,7 @@ import (
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"time"
 
@@ -27,6 +28,8 @@ const (
 	defaultURL    = "https://localhost/cgi-bin/csaf_provider.go"
 	defaultAction = "upload"
 	defaultTLP    = "csaf"
+	// defaultLogFormat is the default format of the log records.
+	defaultLogFormat = options.LogFormatText
 )
 
 // The supported flag config of the uploader command line
@@ -53,6 +56,12 @@ type config struct {
 
 	Insecure bool `long:"insecure" description:"Do not check TLS certificates from provider" toml:"insecure"`
 
+	LogFile string `long:"log_file" description:"FILE to log to (defaults to STDERR)" value-name:"FILE" toml:"log_file"`
+	//lint:ignore SA5008 We are using choice or than once: debug, info, warn, error
+	LogLevel *options.LogLevel `long:"log_level" description:"LEVEL of logging details (defaults to info)" value-name:"LEVEL" choice:"debug" choice:"info" choice:"warn" choice:"error" toml:"log_level"`
+	//lint:ignore SA5008 We are using choice twice: text, json
+	LogFormat options.LogFormat `long:"log_format" description:"FORMAT of the log records" value-name:"FORMAT" choice:"text" choice:"json" toml:"log_format"`
+
 	Config  string `short:"c" long:"config" description:"Path to config TOML file" value-name:"TOML-FILE" toml:"-"`
 	Version bool   `long:"version" description:"Display version of the binary" toml:"-"`
 
@@ -79,6 +88,7 @@ func parseArgsConfig() ([]string, *config, error) {
 			cfg.URL = defaultURL
 			cfg.Action = defaultAction
 			cfg.TLP = defaultTLP
+			cfg.LogFormat = defaultLogFormat
 		},
 		// Re-establish default values if not set.
 		EnsureDefaults: func(cfg *config) {
@@ -91,11 +101,27 @@ func parseArgsConfig() ([]string, *config, error) {
 			if cfg.TLP == "" {
 				cfg.TLP = defaultTLP
 			}
+			if cfg.LogFormat == "" {
+				cfg.LogFormat = defaultLogFormat
+			}
 		},
 	}
 	return p.Parse()
 }
 
+// prepareLogging sets up the structured logging.
+func (cfg *config) prepareLogging() error {
+	lg := options.Logging{
+		Format: cfg.LogFormat,
+		Level:  slog.LevelInfo,
+		File:   cfg.LogFile,
+	}
+	if cfg.LogLevel != nil {
+		lg.Level = cfg.LogLevel.Level
+	}
+	return lg.SetDefault()
+}
+
 // prepareCertificates loads the client side certificates used by the HTTP client.
 func (cfg *config) prepareCertificates() error {
 	cert, err := certs.LoadCertificate(
@@ -181,6 +207,7 @@ func (cfg *config) preparePassword() error {
 // prepare prepares internal state of a loaded configuration.
 func (cfg *config) prepare() error {
 	for _, prepare := range []func(*config) error{
+		(*config).prepareLogging,
 		(*config).prepareCertificates,
 		(*config).prepareInteractive,
 		(*config).prepareOpenPGPKey,
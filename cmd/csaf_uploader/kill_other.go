@@ -11,11 +11,11 @@
 package main
 
 import (
-	"log"
+	"log/slog"
 	"os/exec"
 )
 
 // prepareKillingProcessGroup is currently not implemented on other platforms than unix-likes.
 func prepareKillingProcessGroup(*exec.Cmd) {
-	log.Println("Warning: creating a process group is not implemented on this platform.")
+	slog.Warn("Creating a process group is not implemented on this platform.")
 }
@@ -14,7 +14,7 @@ import "github.com/gocsaf/csaf/v3/internal/options"
 func main() {
 	args, cfg, err := parseArgsConfig()
 	options.ErrorCheck(err)
-	options.ErrorCheck(cfg.prepare())
+	options.ErrorCheckStructured(cfg.prepare())
 	p := &processor{cfg: cfg}
-	options.ErrorCheck(p.run(args))
+	options.ErrorCheckStructured(p.run(args))
 }
@@ -15,7 +15,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
 	"os"
@@ -304,7 +304,7 @@ func (p *processor) run(args []string) error {
 	}
 
 	if len(args) == 0 {
-		log.Println("No CSAF files given.")
+		slog.Warn("No CSAF files given.")
 	}
 
 	for _, arg := range args {
@@ -14,7 +14,7 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -25,6 +25,7 @@ import (
 
 	"github.com/gocsaf/csaf/v3/csaf"
 	"github.com/gocsaf/csaf/v3/internal/misc"
+	"github.com/gocsaf/csaf/v3/internal/options"
 	"github.com/gocsaf/csaf/v3/util"
 )
 
@@ -35,16 +36,22 @@ const (
 	exitCodeAllValid = 0
 )
 
-type options struct {
+type cliOptions struct {
 	Version                bool     `long:"version" description:"Display version of the binary"`
 	RemoteValidator        string   `long:"validator" description:"URL to validate documents remotely" value-name:"URL"`
 	RemoteValidatorCache   string   `long:"validator_cache" description:"FILE to cache remote validations" value-name:"FILE"`
 	RemoteValidatorPresets []string `long:"validator_preset" description:"One or more presets to validate remotely" default:"mandatory"`
 	Output                 string   `short:"o" long:"output" description:"If a remote validator was used, display AMOUNT ('all', 'important' or 'short') results" value-name:"AMOUNT"`
+
+	LogFile string `long:"log_file" description:"FILE to log to (defaults to STDERR)" value-name:"FILE"`
+	//lint:ignore SA5008 We are using choice or than once: debug, info, warn, error
+	LogLevel options.LogLevel `long:"log_level" description:"LEVEL of logging details" value-name:"LEVEL" choice:"debug" choice:"info" choice:"warn" choice:"error" default:"info"`
+	//lint:ignore SA5008 We are using choice twice: text, json
+	LogFormat options.LogFormat `long:"log_format" description:"FORMAT of the log records" value-name:"FORMAT" choice:"text" choice:"json" default:"text"`
 }
 
 func main() {
-	opts := new(options)
+	opts := new(cliOptions)
 
 	parser := flags.NewParser(opts, flags.Default)
 	parser.Usage = "[OPTIONS] files..."
@@ -56,8 +63,15 @@ func main() {
 		return
 	}
 
+	lg := options.Logging{
+		Format: opts.LogFormat,
+		Level:  opts.LogLevel.Level,
+		File:   opts.LogFile,
+	}
+	errCheck(lg.SetDefault())
+
 	if len(files) == 0 {
-		log.Println("No files given.")
+		slog.Warn("No files given.")
 		return
 	}
 
@@ -65,7 +79,7 @@ func main() {
 }
 
 // run validates the given files.
-func run(opts *options, files []string) error {
+func run(opts *cliOptions, files []string) error {
 	exitCode := exitCodeAllValid
 
 	var validator csaf.RemoteValidatorWithContext
@@ -90,7 +104,7 @@ func run(opts *options, files []string) error {
 		defer validator.Close()
 	} else {
 		exitCode |= exitCodeNoRemoteValidator
-		log.Printf("warn: no remote validator specified")
+		slog.Warn("No remote validator specified")
 	}
 
 	// Select amount level of output for remote validation.
@@ -116,20 +130,19 @@ func run(opts *options, files []string) error {
 
 		doc, raw, err := loadJSONFromFile(file)
 		if err != nil {
-			log.Printf("error: loading %q as JSON failed: %v\n", file, err)
+			slog.Error("Loading JSON failed", "file", file, "error", err)
 			continue
 		}
 
 		// Check for invalid UTF-8 in file
 		if !utf8.Valid(raw) {
-			log.Printf("file %s contains invalid UTF-8", file)
+			slog.Warn("File contains invalid UTF-8", "file", file)
 		}
 
 		// Validate against Schema.
 		validationErrs, err := csaf.ValidateCSAF(doc)
 		if err != nil {
-			log.Printf("error: validating %q against schema failed: %v\n",
-				file, err)
+			slog.Error("Validating against schema failed", "file", file, "error", err)
 
 		}
 		if len(validationErrs) > 0 {
@@ -144,7 +157,7 @@ func run(opts *options, files []string) error {
 
 		// Check filename against ID
 		if err := util.IDMatchesFilename(eval, doc, filepath.Base(file)); err != nil {
-			log.Printf("%s: %s.\n", file, err)
+			slog.Warn("ID does not match filename", "file", file, "error", err)
 		}
 
 		// Validate against remote validator.
@@ -303,7 +316,7 @@ func errCheck(err error) {
 		if flags.WroteHelp(err) {
 			os.Exit(0)
 		}
-		log.Fatalf("error: %v\n", err)
+		options.ErrorCheckStructured(err)
 	}
 }
 
@@ -153,7 +153,7 @@ func (afp *AdvisoryFileProcessor) ProcessWithContext(
 	rolie, err := afp.expr.Eval(
 		"$.distributions[*].rolie.feeds", afp.doc)
 	if err != nil {
-		lg(slog.LevelError, "rolie check failed", "err", err)
+		lg(slog.LevelError, "rolie check failed", "error", err)
 		return err
 	}
 
@@ -181,7 +181,7 @@ func (afp *AdvisoryFileProcessor) ProcessWithContext(
 		var dirURLs []string
 
 		if err != nil {
-			lg(slog.LevelError, "extracting directory URLs failed", "err", err)
+			lg(slog.LevelError, "extracting directory URLs failed", "error", err)
 		} else {
 			var ok bool
 			dirURLs, ok = util.AsStrings(directoryURLs)
@@ -267,7 +267,7 @@ func (afp *AdvisoryFileProcessor) loadChanges(
 		}
 		t, err := time.Parse(time.RFC3339, r[timeColumn])
 		if err != nil {
-			lg(slog.LevelError, "Invalid time stamp in line", "url", changesURL, "line", line, "err", err)
+			lg(slog.LevelError, "Invalid time stamp in line", "url", changesURL, "line", line, "error", err)
 			continue
 		}
 		// Apply date range filtering.
@@ -303,14 +303,14 @@ func (afp *AdvisoryFileProcessor) processROLIE(
 		}
 		feedURL, err := url.Parse(string(*feed.URL))
 		if err != nil {
-			slog.Error("Invalid URL in feed", "feed", *feed.URL, "err", err)
+			slog.Error("Invalid URL in feed", "feed", *feed.URL, "error", err)
 			continue
 		}
 		slog.Info("Got feed URL", "feed", feedURL)
 
 		fb, err := util.BaseURL(feedURL)
 		if err != nil {
-			slog.Error("Invalid feed base URL", "url", fb, "err", err)
+			slog.Error("Invalid feed base URL", "url", fb, "error", err)
 			continue
 		}
 
@@ -321,7 +321,7 @@ func (afp *AdvisoryFileProcessor) processROLIE(
 			res, err = afp.client.Get(feedURL.String())
 		}
 		if err != nil {
-			slog.Error("Cannot get feed", "err", err)
+			slog.Error("Cannot get feed", "error", err)
 			continue
 		}
 		if res.StatusCode != http.StatusOK {
@@ -333,11 +333,11 @@ func (afp *AdvisoryFileProcessor) processROLIE(
 		var files []AdvisoryFile
 		if afp.StreamingROLIEParser {
 			if err := afp.processROLIEStream(&files, res); err != nil {
-				slog.Error("Streaming ROLIE feed failed", "err", err)
+				slog.Error("Streaming ROLIE feed failed", "error", err)
 				continue
 			}
 		} else if err := afp.processROLIELegacy(&files, res); err != nil {
-			slog.Error("Loading ROLIE feed failed", "err", err)
+			slog.Error("Loading ROLIE feed failed", "error", err)
 			continue
 		}
 
@@ -469,7 +469,7 @@ func (afp *AdvisoryFileProcessor) resolveURL(u string) string {
 	}
 	p, err := url.Parse(u)
 	if err != nil {
-		slog.Error("Invalid URL", "url", u, "err", err)
+		slog.Error("Invalid URL", "url", u, "error", err)
 		return ""
 	}
 	return p.String()
@@ -0,0 +1,437 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2022 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2022 Intevation GmbH <https://intevation.de>
+
+// Package indices writes the indices of a provider-like
+// directory tree of CSAF advisories.
+package indices
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocsaf/csaf/v3/csaf"
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+const (
+	// InterimsCSV is the name of the file to store the URLs
+	// of the interim advisories.
+	InterimsCSV = "interims.csv"
+
+	// ChangesCSV is the name of the file to store the
+	// the paths to the advisories sorted in descending order
+	// of the release date along with the release date.
+	ChangesCSV = "changes.csv"
+
+	// IndexTXT is the name of the file to store the
+	// the paths of the advisories.
+	IndexTXT = "index.txt"
+
+	// ServiceJSON is the name of the ROLIE service document.
+	ServiceJSON = "service.json"
+)
+
+// IsIndexFile returns true if name is the name
+// of a file written by a [Writer].
+func IsIndexFile(name string) bool {
+	switch name {
+	case InterimsCSV, ChangesCSV, IndexTXT, ServiceJSON:
+		return true
+	}
+	return strings.HasSuffix(name, ".json") &&
+		(strings.HasPrefix(name, "csaf-feed-tlp-") || strings.HasPrefix(name, "category-"))
+}
+
+// Summary is an advisory listed in the indices.
+// The advisory is expected to be stored in
+// <label>/<year of initial release>/<filename>.
+type Summary struct {
+	Filename string
+	Summary  *csaf.AdvisorySummary
+	// URL is where the advisory was fetched from.
+	URL string
+	// Unsigned is true if there is no signature next to the advisory.
+	Unsigned bool
+}
+
+// path returns the path of the advisory relative to the label folder.
+func (s *Summary) path() string {
+	return strconv.Itoa(s.Summary.InitialReleaseDate.Year()) + "/" + s.Filename
+}
+
+// Writer writes the indices of a directory tree.
+type Writer struct {
+	// Dir is the root folder of the tree.
+	Dir string
+	// BaseURL is the URL the tree is served at.
+	BaseURL *url.URL
+	// Indices enables writing index.txt and changes.csv.
+	Indices bool
+	// Service enables writing a ROLIE service document.
+	Service bool
+	// Log is used to log the progress. Defaults to slog.Default().
+	Log *slog.Logger
+}
+
+func (w *Writer) log() *slog.Logger {
+	if w.Log != nil {
+		return w.Log
+	}
+	return slog.Default()
+}
+
+// WriteHash writes a hash in the format of sha256sum to file.
+func WriteHash(fname, name string, hash []byte) error {
+	f, err := os.Create(fname)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(f, "%x %s\n", hash, name)
+	return f.Close()
+}
+
+// WriteInterims writes the interims.csv of a label.
+func (w *Writer) WriteInterims(label string, summaries []Summary) error {
+
+	// Filter out the interims.
+	var ss []Summary
+	for _, s := range summaries {
+		if s.Summary.Status == "interim" {
+			ss = append(ss, s)
+		}
+	}
+
+	// No interims -> nothing to write
+	if len(ss) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(ss, func(i, j int) bool {
+		return ss[i].Summary.CurrentReleaseDate.After(
+			ss[j].Summary.CurrentReleaseDate)
+	})
+
+	fname := filepath.Join(w.Dir, label, InterimsCSV)
+	f, err := os.Create(fname)
+	if err != nil {
+		return err
+	}
+	out := csv.NewWriter(f)
+
+	record := make([]string, 3)
+
+	for i := range ss {
+		s := &ss[i]
+		record[0] =
+			s.Summary.CurrentReleaseDate.Format(time.RFC3339)
+		record[1] = s.path()
+		record[2] = s.URL
+		if err := out.Write(record); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	out.Flush()
+	err1 := out.Error()
+	err2 := f.Close()
+	if err1 != nil {
+		return err1
+	}
+	return err2
+}
+
+// WriteCSV writes the changes.csv of a label.
+func (w *Writer) WriteCSV(label string, summaries []Summary) error {
+
+	fname := filepath.Join(w.Dir, label, ChangesCSV)
+
+	// If we don't have any entries remove existing file.
+	if len(summaries) == 0 {
+		// Does it really exist?
+		if err := os.RemoveAll(fname); err != nil {
+			return fmt.Errorf("unable to remove %q: %w", fname, err)
+		}
+		return nil
+	}
+
+	f, err := os.Create(fname)
+	if err != nil {
+		return err
+	}
+
+	// Do not sort in-place.
+	ss := make([]Summary, len(summaries))
+	copy(ss, summaries)
+
+	sort.SliceStable(ss, func(i, j int) bool {
+		return ss[i].Summary.CurrentReleaseDate.After(
+			ss[j].Summary.CurrentReleaseDate)
+	})
+
+	out := util.NewFullyQuotedCSWWriter(f)
+
+	record := make([]string, 2)
+
+	const (
+		pathColumn = 0
+		timeColumn = 1
+	)
+
+	for i := range ss {
+		s := &ss[i]
+		record[pathColumn] = s.path()
+		record[timeColumn] =
+			s.Summary.CurrentReleaseDate.Format(time.RFC3339)
+		if err := out.Write(record); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	out.Flush()
+	err1 := out.Error()
+	err2 := f.Close()
+	if err1 != nil {
+		return err1
+	}
+	return err2
+}
+
+// WriteIndex writes the index.txt of a label.
+func (w *Writer) WriteIndex(label string, summaries []Summary) error {
+
+	fname := filepath.Join(w.Dir, label, IndexTXT)
+
+	// If we don't have any entries remove existing file.
+	if len(summaries) == 0 {
+		// Does it really exist?
+		if err := os.RemoveAll(fname); err != nil {
+			return fmt.Errorf("unable to remove %q: %w", fname, err)
+		}
+		return nil
+	}
+
+	f, err := os.Create(fname)
+	if err != nil {
+		return err
+	}
+	out := bufio.NewWriter(f)
+	for i := range summaries {
+		fmt.Fprintln(out, summaries[i].path())
+	}
+	err1 := out.Flush()
+	err2 := f.Close()
+	if err1 != nil {
+		return err1
+	}
+	return err2
+}
+
+// feedLinks returns the links of the ROLIE feed of a label.
+func (w *Writer) feedLinks(labelFolder, fname string) []csaf.Link {
+	links := []csaf.Link{{
+		Rel:  "self",
+		HRef: w.BaseURL.JoinPath(labelFolder, fname).String(),
+	}}
+	if w.Service {
+		links = append(links, csaf.Link{
+			Rel:  "service",
+			HRef: w.BaseURL.JoinPath(ServiceJSON).String(),
+		})
+	}
+	return links
+}
+
+// writeFeed writes the ROLIE feed of a label with the given entries.
+func (w *Writer) writeFeed(label string, entries []*csaf.Entry) error {
+
+	labelFolder := strings.ToLower(label)
+
+	fname := "csaf-feed-tlp-" + labelFolder + ".json"
+
+	rolie := &csaf.ROLIEFeed{
+		Feed: csaf.FeedData{
+			ID:    "csaf-feed-tlp-" + labelFolder,
+			Title: "CSAF feed (TLP:" + strings.ToUpper(label) + ")",
+			Link:  w.feedLinks(labelFolder, fname),
+			Category: []csaf.ROLIECategory{{
+				Scheme: "urn:ietf:params:rolie:category:information-type",
+				Term:   "csaf",
+			}},
+			Updated: csaf.TimeStamp(time.Now().UTC()),
+			Entry:   entries,
+		},
+	}
+
+	// Sort by descending updated order.
+	rolie.SortEntriesByUpdated()
+
+	path := filepath.Join(w.Dir, labelFolder, fname)
+	return util.WriteToFile(path, rolie)
+}
+
+// WriteROLIENoSummaries writes an empty ROLIE feed of a label.
+func (w *Writer) WriteROLIENoSummaries(label string) error {
+	return w.writeFeed(label, []*csaf.Entry{})
+}
+
+// WriteROLIE writes the ROLIE feed of a label.
+func (w *Writer) WriteROLIE(label string, summaries []Summary) error {
+
+	entries := make([]*csaf.Entry, len(summaries))
+
+	format := csaf.Format{
+		Schema:  "https://docs.oasis-open.org/csaf/csaf/v2.0/csaf_json_schema.json",
+		Version: "2.0",
+	}
+
+	for i := range summaries {
+		s := &summaries[i]
+
+		csafURLString := w.BaseURL.JoinPath(label,
+			strconv.Itoa(s.Summary.InitialReleaseDate.Year()),
+			s.Filename).String()
+
+		links := []csaf.Link{
+			{Rel: "self", HRef: csafURLString},
+			{Rel: "hash", HRef: csafURLString + ".sha256"},
+			{Rel: "hash", HRef: csafURLString + ".sha512"},
+		}
+		if !s.Unsigned {
+			links = append(links, csaf.Link{Rel: "signature", HRef: csafURLString + ".asc"})
+		}
+
+		entries[i] = &csaf.Entry{
+			ID:        s.Summary.ID,
+			Titel:     s.Summary.Title,
+			Published: csaf.TimeStamp(s.Summary.InitialReleaseDate),
+			Updated:   csaf.TimeStamp(s.Summary.CurrentReleaseDate),
+			Link:      links,
+			Format:    format,
+			Content: csaf.Content{
+				Type: "application/json",
+				Src:  csafURLString,
+			},
+		}
+		if s.Summary.Summary != "" {
+			entries[i].Summary = &csaf.Summary{
+				Content: s.Summary.Summary,
+			}
+		}
+	}
+
+	return w.writeFeed(label, entries)
+}
+
+// WriteCategories writes the ROLIE category document of a label.
+func (w *Writer) WriteCategories(label string, categories util.Set[string]) error {
+	if len(categories) == 0 {
+		return nil
+	}
+	cats := make([]string, len(categories))
+	var i int
+	for cat := range categories {
+		cats[i] = cat
+		i++
+	}
+	rcd := csaf.NewROLIECategoryDocument(cats...)
+
+	labelFolder := strings.ToLower(label)
+	fname := "category-" + labelFolder + ".json"
+	path := filepath.Join(w.Dir, labelFolder, fname)
+	return util.WriteToFile(path, rcd)
+}
+
+// WriteService writes a service.json document for the given labels
+// if it is configured.
+func (w *Writer) WriteService(labels []string) error {
+
+	if !w.Service {
+		return nil
+	}
+	ls := make([]string, len(labels))
+	for i, label := range labels {
+		ls[i] = strings.ToLower(label)
+	}
+	sort.Strings(ls)
+
+	categories := csaf.ROLIEServiceWorkspaceCollectionCategories{
+		Category: []csaf.ROLIEServiceWorkspaceCollectionCategoriesCategory{{
+			Scheme: "urn:ietf:params:rolie:category:information-type",
+			Term:   "csaf",
+		}},
+	}
+
+	var collections []csaf.ROLIEServiceWorkspaceCollection
+
+	for _, ts := range ls {
+		feedName := "csaf-feed-tlp-" + ts + ".json"
+
+		collection := csaf.ROLIEServiceWorkspaceCollection{
+			Title:      "CSAF feed (TLP:" + strings.ToUpper(ts) + ")",
+			HRef:       w.BaseURL.JoinPath(ts, feedName).String(),
+			Categories: categories,
+		}
+		collections = append(collections, collection)
+	}
+
+	rsd := &csaf.ROLIEServiceDocument{
+		Service: csaf.ROLIEService{
+			Workspace: []csaf.ROLIEServiceWorkspace{{
+				Title:      "CSAF feeds",
+				Collection: collections,
+			}},
+		},
+	}
+
+	path := filepath.Join(w.Dir, ServiceJSON)
+	return util.WriteToFile(path, rsd)
+}
+
+// Write writes all indices for the given summaries and
+// categories per label.
+func (w *Writer) Write(
+	summaries map[string][]Summary,
+	categories map[string]util.Set[string],
+) error {
+
+	labels := make([]string, 0, len(summaries))
+
+	for label, ss := range summaries {
+		labels = append(labels, label)
+		w.log().Debug("Writing indices", "label", label, "summaries.num", len(ss))
+		if err := w.WriteInterims(label, ss); err != nil {
+			return err
+		}
+		// Only write index.txt and changes.csv if configured.
+		if w.Indices {
+			if err := w.WriteCSV(label, ss); err != nil {
+				return err
+			}
+			if err := w.WriteIndex(label, ss); err != nil {
+				return err
+			}
+		}
+		if err := w.WriteROLIE(label, ss); err != nil {
+			return err
+		}
+		if err := w.WriteCategories(label, categories[label]); err != nil {
+			return err
+		}
+	}
+
+	return w.WriteService(labels)
+}
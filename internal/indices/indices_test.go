@@ -0,0 +1,101 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package indices
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gocsaf/csaf/v3/csaf"
+)
+
+func TestIsIndexFile(t *testing.T) {
+	for name, want := range map[string]bool{
+		"index.txt":                  true,
+		"changes.csv":                true,
+		"csaf-feed-tlp-white.json":   true,
+		"category-white.json":        true,
+		"avendor-advisory-0004.json": false,
+	} {
+		if got := IsIndexFile(name); got != want {
+			t.Errorf("%s: got %t, want %t", name, got, want)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	base, _ := url.Parse("https://example.com/csaf")
+	w := &Writer{Dir: dir, BaseURL: base, Indices: true, Service: true}
+
+	date := func(s string) time.Time {
+		d, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	summaries := map[string][]Summary{
+		"white": {{
+			Filename: "a-0001.json",
+			Summary: &csaf.AdvisorySummary{
+				ID:                 "A-0001",
+				InitialReleaseDate: date("2020-01-01T00:00:00Z"),
+				CurrentReleaseDate: date("2020-01-01T00:00:00Z"),
+				Status:             "final",
+			},
+		}, {
+			Filename: "a-0002.json",
+			Summary: &csaf.AdvisorySummary{
+				ID:                 "A-0002",
+				InitialReleaseDate: date("2021-01-01T00:00:00Z"),
+				CurrentReleaseDate: date("2022-01-01T00:00:00Z"),
+				Status:             "interim",
+			},
+		}},
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "white"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(summaries, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{
+		"white/index.txt":    "2020/a-0001.json\n2021/a-0002.json\n",
+		"white/changes.csv":  "\"2021/a-0002.json\",\"2022-01-01T00:00:00Z\"\n\"2020/a-0001.json\",\"2020-01-01T00:00:00Z\"\n",
+		"white/interims.csv": "2022-01-01T00:00:00Z,2021/a-0002.json,\n",
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("%s: got %q, want %q", name, data, want)
+		}
+	}
+	for _, name := range []string{"white/csaf-feed-tlp-white.json", "service.json"} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// Emptied labels lose their index.
+	summaries["white"] = nil
+	if err := w.Write(summaries, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "white", IndexTXT)); !os.IsNotExist(err) {
+		t.Errorf("index.txt not removed: %v", err)
+	}
+}
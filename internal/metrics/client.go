@@ -0,0 +1,93 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gocsaf/csaf/v3/util"
+)
+
+// CountingClient is a Client counting the requests by host
+// and status code. Requests failing without a response are
+// counted with the status "error".
+type CountingClient struct {
+	util.Client
+	Requests *Counter
+}
+
+// Do implements the respective method of the [util.Client] interface.
+func (cc *CountingClient) Do(req *http.Request) (*http.Response, error) {
+	res, err := cc.Client.Do(req)
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(res.StatusCode)
+	}
+	cc.Requests.Inc(strings.ToLower(req.URL.Hostname()), status)
+	return res, err
+}
+
+// GetWithContext implements the respective method of the [util.ClientWithContext] interface.
+func (cc *CountingClient) GetWithContext(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return cc.Do(req)
+}
+
+// Get implements the respective method of the [util.Client] interface.
+func (cc *CountingClient) Get(url string) (*http.Response, error) {
+	return cc.GetWithContext(context.Background(), url)
+}
+
+// HeadWithContext implements the respective method of the [util.ClientWithContext] interface.
+func (cc *CountingClient) HeadWithContext(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return cc.Do(req)
+}
+
+// Head implements the respective method of the [util.Client] interface.
+func (cc *CountingClient) Head(url string) (*http.Response, error) {
+	return cc.HeadWithContext(context.Background(), url)
+}
+
+// PostWithContext implements the respective method of the [util.ClientWithContext] interface.
+func (cc *CountingClient) PostWithContext(ctx context.Context, url, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return cc.Do(req)
+}
+
+// Post implements the respective method of the [util.Client] interface.
+func (cc *CountingClient) Post(url, contentType string, body io.Reader) (*http.Response, error) {
+	return cc.PostWithContext(context.Background(), url, contentType, body)
+}
+
+// PostFormWithContext implements the respective method of the [util.ClientWithContext] interface.
+func (cc *CountingClient) PostFormWithContext(ctx context.Context, url string, data url.Values) (*http.Response, error) {
+	return cc.PostWithContext(
+		ctx, url, "application/x-www-form-urlencoded", strings.NewReader(data.Encode()))
+}
+
+// PostForm implements the respective method of the [util.Client] interface.
+func (cc *CountingClient) PostForm(url string, data url.Values) (*http.Response, error) {
+	return cc.PostFormWithContext(context.Background(), url, data)
+}
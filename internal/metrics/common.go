@@ -0,0 +1,41 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package metrics
+
+// The metrics shared by the tools are registered
+// here to keep their names and labels in sync.
+
+// Requests registers the counter of the HTTP requests
+// by host and status to be used with a [CountingClient].
+func (r *Registry) Requests() *Counter {
+	return r.Counter("csaf_http_requests_total",
+		"Number of HTTP requests by host and status code.",
+		"host", "status")
+}
+
+// Processed registers the counter of the processed advisories by result.
+func (r *Registry) Processed() *Counter {
+	return r.Counter("csaf_advisories_processed_total",
+		"Number of processed advisories by result.",
+		"result")
+}
+
+// Failed registers the counter of the failed advisories by reason.
+func (r *Registry) Failed() *Counter {
+	return r.Counter("csaf_advisories_failed_total",
+		"Number of failed advisories by reason.",
+		"reason")
+}
+
+// QueueDepth registers the gauge of the entries waiting in queues.
+func (r *Registry) QueueDepth() *Gauge {
+	return r.Gauge("csaf_queue_depth",
+		"Number of entries waiting in a queue.",
+		"queue")
+}
@@ -0,0 +1,221 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package metrics implements counters and gauges which are
+// exposed in the Prometheus text format.
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// contentType is the content type of the Prometheus text format.
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// shutdownTimeout is the time given to the metrics
+// server to finish running requests.
+const shutdownTimeout = 5 * time.Second
+
+// Registry keeps the metrics to be exposed.
+// It is safe for concurrent use.
+type Registry struct {
+	mu       sync.Mutex
+	families []*family
+}
+
+// family is a metric with all its labeled series.
+type family struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+// series is the value of a metric for a set of label values.
+type series struct {
+	values []string
+	value  float64
+}
+
+// Counter is a metric which only goes up.
+// A nil Counter ignores all updates.
+type Counter struct{ f *family }
+
+// Gauge is a metric which goes up and down.
+// A nil Gauge ignores all updates.
+type Gauge struct{ f *family }
+
+// NewRegistry creates a new Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// register adds a new family of metrics.
+func (r *Registry) register(name, help, kind string, labels []string) *family {
+	f := &family{
+		name:   name,
+		help:   help,
+		kind:   kind,
+		labels: labels,
+		series: map[string]*series{},
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.families = append(r.families, f)
+	return f
+}
+
+// Counter registers a new counter with the given label names.
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	return &Counter{f: r.register(name, help, "counter", labels)}
+}
+
+// Gauge registers a new gauge with the given label names.
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{f: r.register(name, help, "gauge", labels)}
+}
+
+// update changes the value of the series with the given label values.
+func (f *family) update(values []string, fn func(float64) float64) {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metric %s: got %d label values, want %d",
+			f.name, len(values), len(f.labels)))
+	}
+	key := strings.Join(values, "\xff")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s := f.series[key]
+	if s == nil {
+		s = &series{values: slices.Clone(values)}
+		f.series[key] = s
+	}
+	s.value = fn(s.value)
+}
+
+// Add adds v to the counter with the given label values.
+// Negative values are ignored.
+func (c *Counter) Add(v float64, values ...string) {
+	if c == nil || v < 0 {
+		return
+	}
+	c.f.update(values, func(x float64) float64 { return x + v })
+}
+
+// Inc increments the counter with the given label values.
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Set sets the gauge with the given label values to v.
+func (g *Gauge) Set(v float64, values ...string) {
+	if g == nil {
+		return
+	}
+	g.f.update(values, func(float64) float64 { return v })
+}
+
+// Add adds v to the gauge with the given label values.
+func (g *Gauge) Add(v float64, values ...string) {
+	if g == nil {
+		return
+	}
+	g.f.update(values, func(x float64) float64 { return x + v })
+}
+
+// escapeHelp escapes the text of a HELP line.
+var escapeHelp = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+// escapeLabel escapes a label value.
+var escapeLabel = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+// write writes the family in the Prometheus text format.
+func (f *family) write(w *bufio.Writer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, escapeHelp.Replace(f.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.kind)
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		s := f.series[key]
+		w.WriteString(f.name)
+		if len(f.labels) > 0 {
+			w.WriteByte('{')
+			for i, label := range f.labels {
+				if i > 0 {
+					w.WriteByte(',')
+				}
+				fmt.Fprintf(w, `%s="%s"`, label, escapeLabel.Replace(s.values[i]))
+			}
+			w.WriteByte('}')
+		}
+		w.WriteByte(' ')
+		w.WriteString(strconv.FormatFloat(s.value, 'g', -1, 64))
+		w.WriteByte('\n')
+	}
+}
+
+// Write writes all metrics in the Prometheus text format.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	families := slices.Clone(r.families)
+	r.mu.Unlock()
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(bw)
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP implements [http.Handler] to serve the metrics.
+func (r *Registry) ServeHTTP(rw http.ResponseWriter, _ *http.Request) {
+	rw.Header().Set("Content-Type", contentType)
+	if err := r.Write(rw); err != nil {
+		slog.Error("Writing metrics failed", "error", err)
+	}
+}
+
+// Serve starts serving the metrics at /metrics on the given address.
+// The returned function shuts the server down.
+func (r *Registry) Serve(addr string) func() {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", r)
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Serving metrics endpoint failed", "error", err)
+		}
+	}()
+	slog.Info("Serving metrics endpoint", "address", addr)
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		srv.Shutdown(ctx)
+	}
+}
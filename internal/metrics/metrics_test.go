@@ -0,0 +1,89 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	r := NewRegistry()
+	failed := r.Counter("csaf_advisories_failed_total", "Failed advisories\nby reason.", "reason")
+	queue := r.Gauge("csaf_queue_depth", "Queue depth.", "queue")
+	total := r.Counter("csaf_runs_total", "Runs.")
+
+	failed.Inc("schema")
+	failed.Add(2, `sig"nature`)
+	failed.Inc("schema")
+	failed.Add(-1, "schema")
+	queue.Set(3, "forward")
+	queue.Add(-1, "forward")
+	total.Inc()
+
+	var nilCounter *Counter
+	nilCounter.Inc("ignored")
+
+	var b strings.Builder
+	if err := r.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP csaf_advisories_failed_total Failed advisories\nby reason.
+# TYPE csaf_advisories_failed_total counter
+csaf_advisories_failed_total{reason="schema"} 2
+csaf_advisories_failed_total{reason="sig\"nature"} 2
+# HELP csaf_queue_depth Queue depth.
+# TYPE csaf_queue_depth gauge
+csaf_queue_depth{queue="forward"} 2
+# HELP csaf_runs_total Runs.
+# TYPE csaf_runs_total counter
+csaf_runs_total 1
+`
+	if got := b.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCountingClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	r := NewRegistry()
+	cc := &CountingClient{Client: server.Client(), Requests: r.Requests()}
+	for _, path := range []string{"/", "/", "/missing"} {
+		res, err := cc.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+	if _, err := cc.Get("http://127.0.0.1:0/"); err == nil {
+		t.Fatal("request to invalid port succeeded")
+	}
+
+	var b strings.Builder
+	if err := r.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`csaf_http_requests_total{host="127.0.0.1",status="200"} 2`,
+		`csaf_http_requests_total{host="127.0.0.1",status="404"} 1`,
+		`csaf_http_requests_total{host="127.0.0.1",status="error"} 1`,
+	} {
+		if !strings.Contains(b.String(), line+"\n") {
+			t.Errorf("missing %q in:\n%s", line, b.String())
+		}
+	}
+}
@@ -9,8 +9,12 @@
 package options
 
 import (
+	"fmt"
+	"io"
 	"log/slog"
+	"os"
 	"strings"
+	"sync"
 )
 
 // LogLevel implements a helper type to be used in configurations.
@@ -31,3 +35,84 @@ func (ll *LogLevel) UnmarshalFlag(value string) error {
 	*ll = LogLevel{Level: l}
 	return nil
 }
+
+// LogFormat is the output format of the logging.
+type LogFormat string
+
+const (
+	// LogFormatText logs lines of key=value pairs.
+	LogFormatText LogFormat = "text"
+	// LogFormatJSON logs one JSON object per line.
+	LogFormatJSON LogFormat = "json"
+)
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (lf *LogFormat) UnmarshalText(text []byte) error {
+	switch f := LogFormat(strings.ToLower(string(text))); f {
+	case LogFormatText, LogFormatJSON:
+		*lf = f
+		return nil
+	}
+	return fmt.Errorf("invalid log format %q", text)
+}
+
+// UnmarshalFlag implements [flags.Unmarshaler].
+func (lf *LogFormat) UnmarshalFlag(value string) error {
+	return lf.UnmarshalText([]byte(value))
+}
+
+// Logging configures the structured logging of the tools.
+type Logging struct {
+	// Format is the output format. Defaults to text.
+	Format LogFormat
+	// Level is the minimal level of the logged records.
+	Level slog.Level
+	// File is the file to append the log to. Defaults to STDERR.
+	File string
+	// ReplaceAttr is passed on to the handler if given.
+	ReplaceAttr func([]string, slog.Attr) slog.Attr
+}
+
+// Handler returns a handler writing to w.
+func (lg *Logging) Handler(w io.Writer) slog.Handler {
+	ho := slog.HandlerOptions{
+		Level:       lg.Level,
+		ReplaceAttr: lg.ReplaceAttr,
+	}
+	if lg.Format == LogFormatJSON {
+		return slog.NewJSONHandler(w, &ho)
+	}
+	return slog.NewTextHandler(w, &ho)
+}
+
+// logFile is the log file opened by the last call of [Logging.SetDefault].
+var (
+	logFileMu sync.Mutex
+	logFile   *os.File
+)
+
+// SetDefault opens the log file if configured and installs
+// a logger writing to it as the default of [slog] and [log].
+// A log file opened by a previous call is closed.
+func (lg *Logging) SetDefault() error {
+	var (
+		w io.Writer = os.Stderr
+		f *os.File
+	)
+	if lg.File != "" {
+		var err error
+		if f, err = os.OpenFile(lg.File, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644); err != nil {
+			return err
+		}
+		w = f
+	}
+	slog.SetDefault(slog.New(lg.Handler(w)))
+
+	logFileMu.Lock()
+	defer logFileMu.Unlock()
+	if logFile != nil {
+		logFile.Close()
+	}
+	logFile = f
+	return nil
+}
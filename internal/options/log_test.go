@@ -9,7 +9,12 @@
 package options
 
 import (
+	"encoding/json"
+	"errors"
 	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -47,3 +52,78 @@ func TestUnmarshalFlag(t *testing.T) {
 		t.Fatal(`"invalid" should return an error`)
 	}
 }
+
+func TestLogFormatUnmarshalFlag(t *testing.T) {
+	for _, x := range []struct {
+		input  string
+		expect LogFormat
+	}{
+		{input: "text", expect: LogFormatText},
+		{input: "json", expect: LogFormatJSON},
+		{input: "JSON", expect: LogFormatJSON},
+	} {
+		var lf LogFormat
+		if err := lf.UnmarshalFlag(x.input); err != nil {
+			t.Fatalf("%q error: %v", x.input, err)
+		}
+		if lf != x.expect {
+			t.Fatalf("%q: got %q expected %q", x.input, lf, x.expect)
+		}
+	}
+	var lf LogFormat
+	if err := lf.UnmarshalFlag("xml"); err == nil {
+		t.Fatal(`"xml" should return an error`)
+	}
+}
+
+func TestLoggingHandler(t *testing.T) {
+	var b strings.Builder
+	lg := Logging{Format: LogFormatJSON, Level: slog.LevelWarn}
+	logger := slog.New(lg.Handler(&b))
+	logger.Info("dropped")
+	logger.Warn("kept", "url", "https://example.com")
+
+	var record map[string]any
+	if err := json.Unmarshal([]byte(b.String()), &record); err != nil {
+		t.Fatalf("no single JSON record: %v: %q", err, b.String())
+	}
+	if record["msg"] != "kept" || record["url"] != "https://example.com" {
+		t.Fatalf("unexpected record: %v", record)
+	}
+
+	b.Reset()
+	lg = Logging{Level: slog.LevelInfo}
+	slog.New(lg.Handler(&b)).Info("text", "domain", "example.com")
+	if got := b.String(); !strings.Contains(got, "msg=text domain=example.com") {
+		t.Fatalf("unexpected text record: %q", got)
+	}
+}
+
+func TestLoggingSetDefault(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+
+	dir := t.TempDir()
+	first := Logging{File: filepath.Join(dir, "first.log")}
+	if err := first.SetDefault(); err != nil {
+		t.Fatal(err)
+	}
+	f := logFile
+	second := Logging{File: filepath.Join(dir, "second.log")}
+	if err := second.SetDefault(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("x"); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("previous log file not closed: %v", err)
+	}
+	f = logFile
+	stderr := Logging{}
+	if err := stderr.SetDefault(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("x"); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("previous log file not closed: %v", err)
+	}
+	if logFile != nil {
+		t.Error("logging to STDERR remembered a log file")
+	}
+}
@@ -119,7 +119,7 @@ func findConfigFile(locations []string) string {
 	for _, f := range locations {
 		name, err := homedir.Expand(f)
 		if err != nil {
-			log.Printf("warn: %v\n", err)
+			slog.Warn("Expanding config location failed", "location", f, "error", err)
 			continue
 		}
 		if _, err := os.Stat(name); err == nil {
@@ -154,7 +154,7 @@ func ErrorCheck(err error) {
 // non-structured Go logging.
 func ErrorCheckStructured(err error) {
 	if err != nil {
-		slog.Error("Error while executing program", "err", err)
+		slog.Error("Error while executing program", "error", err)
 		os.Exit(1)
 	}
 }
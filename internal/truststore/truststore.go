@@ -0,0 +1,252 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+// Package truststore implements a local store of pinned OpenPGP
+// key fingerprints of CSAF providers.
+package truststore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+// Policy defines how keys are treated which are not pinned.
+type Policy string
+
+const (
+	// PolicyTOFU pins the keys of unknown providers on first use.
+	// Keys not pinned for known providers are reported but used.
+	PolicyTOFU = Policy("tofu")
+	// PolicyReject pins the keys of unknown providers on first use.
+	// Keys not pinned for known providers are reported and not used.
+	PolicyReject = Policy("reject")
+	// PolicyStrict only uses pinned keys. Nothing is pinned automatically.
+	PolicyStrict = Policy("strict")
+)
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (p *Policy) UnmarshalText(text []byte) error {
+	switch v := Policy(text); v {
+	case PolicyTOFU, PolicyReject, PolicyStrict:
+		*p = v
+	default:
+		return fmt.Errorf(`invalid value %q (expected "tofu", "reject" or "strict")`, v)
+	}
+	return nil
+}
+
+// UnmarshalFlag implements [flags.UnmarshalFlag].
+func (p *Policy) UnmarshalFlag(value string) error {
+	return p.UnmarshalText([]byte(value))
+}
+
+// Pin is a pinned key fingerprint.
+type Pin struct {
+	Fingerprint string    `json:"fingerprint"`
+	Pinned      time.Time `json:"pinned,omitzero"`
+	// Source is "tofu" for keys pinned on first use.
+	Source string `json:"source,omitempty"`
+}
+
+// storeFile is the on-disk format of the store.
+type storeFile struct {
+	Providers map[string][]*Pin `json:"providers"`
+}
+
+// Store maps provider domains to pinned key fingerprints.
+// It is safe for concurrent use.
+type Store struct {
+	path   string
+	policy Policy
+
+	mu        sync.Mutex
+	providers map[string][]*Pin
+}
+
+// Open loads the store from the given file. A missing file
+// is treated as an empty store which is created on first pinning.
+func Open(path string, policy Policy) (*Store, error) {
+	if policy == "" {
+		policy = PolicyTOFU
+	}
+	s := &Store{
+		path:      path,
+		policy:    policy,
+		providers: map[string][]*Pin{},
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return s, nil
+		}
+		return nil, err
+	}
+	var sf storeFile
+	if err := json.Unmarshal(data, &sf); err != nil {
+		return nil, fmt.Errorf("loading trust store %q failed: %w", path, err)
+	}
+	for domain, pins := range sf.Providers {
+		s.providers[strings.ToLower(domain)] = pins
+	}
+	return s, nil
+}
+
+// Policy returns the policy of the store.
+func (s *Store) Policy() Policy {
+	return s.policy
+}
+
+// Pinned returns the pinned fingerprints of a provider.
+func (s *Store) Pinned(domain string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pins := s.providers[strings.ToLower(domain)]
+	fps := make([]string, 0, len(pins))
+	for _, pin := range pins {
+		fps = append(fps, pin.Fingerprint)
+	}
+	return fps
+}
+
+// Filter returns the keys of a provider which may be used according
+// to the policy. Keys of unknown providers are pinned on first use
+// unless the policy is strict. Keys which are not pinned and pinned
+// keys no longer offered by the provider are reported.
+func (s *Store) Filter(domain string, keys []*crypto.Key) ([]*crypto.Key, error) {
+	domain = strings.ToLower(domain)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pins := s.providers[domain]
+
+	if len(pins) == 0 {
+		if s.policy == PolicyStrict {
+			if len(keys) > 0 {
+				slog.Warn("No OpenPGP keys pinned for provider",
+					"domain", domain)
+			}
+			return nil, nil
+		}
+		if len(keys) == 0 {
+			return nil, nil
+		}
+		now := time.Now().UTC()
+		for _, key := range keys {
+			pins = append(pins, &Pin{
+				Fingerprint: strings.ToLower(key.GetFingerprint()),
+				Pinned:      now,
+				Source:      "tofu",
+			})
+			slog.Info("Pinned OpenPGP key on first use",
+				"domain", domain,
+				"fingerprint", key.GetFingerprint())
+		}
+		s.providers[domain] = pins
+		if err := s.save(); err != nil {
+			return nil, err
+		}
+		return keys, nil
+	}
+
+	isPinned := func(fp string) bool {
+		return slices.ContainsFunc(pins, func(pin *Pin) bool {
+			return strings.EqualFold(pin.Fingerprint, fp)
+		})
+	}
+
+	var accepted []*crypto.Key
+	for _, key := range keys {
+		if isPinned(key.GetFingerprint()) {
+			accepted = append(accepted, key)
+			continue
+		}
+		slog.Warn("OpenPGP key of provider is not pinned",
+			"domain", domain,
+			"fingerprint", key.GetFingerprint(),
+			"policy", s.policy)
+		if s.policy == PolicyTOFU {
+			accepted = append(accepted, key)
+		}
+	}
+
+	for _, pin := range pins {
+		if !slices.ContainsFunc(keys, func(key *crypto.Key) bool {
+			return strings.EqualFold(pin.Fingerprint, key.GetFingerprint())
+		}) {
+			slog.Warn("Pinned OpenPGP key no longer offered by provider",
+				"domain", domain,
+				"fingerprint", pin.Fingerprint)
+		}
+	}
+	return accepted, nil
+}
+
+// KeyRing returns a key ring of the keys of a provider which may
+// be used according to the policy. It returns nil if signatures
+// of the provider cannot be checked as it offers no keys and
+// none are required by the store.
+func (s *Store) KeyRing(domain string, keys []*crypto.Key) (*crypto.KeyRing, error) {
+	accepted, err := s.Filter(domain, keys)
+	if err != nil {
+		return nil, err
+	}
+	if len(accepted) == 0 && len(s.Pinned(domain)) == 0 && s.policy != PolicyStrict {
+		return nil, nil
+	}
+	// An empty key ring lets all signature checks fail.
+	ring, err := crypto.NewKeyRing(nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range accepted {
+		if err := ring.AddKey(key); err != nil {
+			return nil, err
+		}
+	}
+	return ring, nil
+}
+
+// save writes the store to its file.
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(storeFile{Providers: s.providers}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(s.path), "."+filepath.Base(s.path)+"-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("saving trust store failed: %w", err)
+	}
+	return nil
+}
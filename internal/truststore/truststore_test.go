@@ -0,0 +1,86 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package truststore
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+func generateKey(t *testing.T) *crypto.Key {
+	t.Helper()
+	key, err := crypto.GenerateKey("test", "test@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := key.ToPublic()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pub
+}
+
+func TestStore(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "trust.json")
+	first, second := generateKey(t), generateKey(t)
+
+	for _, tc := range []struct {
+		name     string
+		policy   Policy
+		keys     []*crypto.Key
+		accepted int
+		pinned   int
+		ring     bool
+	}{
+		{"strict unknown", PolicyStrict, []*crypto.Key{first}, 0, 0, true},
+		{"unknown without keys", PolicyTOFU, nil, 0, 0, false},
+		{"first use", PolicyTOFU, []*crypto.Key{first}, 1, 1, true},
+		{"tofu unpinned", PolicyTOFU, []*crypto.Key{first, second}, 2, 1, true},
+		{"reject unpinned", PolicyReject, []*crypto.Key{first, second}, 1, 1, true},
+		{"strict pinned", PolicyStrict, []*crypto.Key{second, first}, 1, 1, true},
+		{"pinned key gone", PolicyReject, []*crypto.Key{second}, 0, 1, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Re-open to check the pins are persisted.
+			s, err := Open(fname, tc.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ring, err := s.KeyRing("Example.COM", tc.keys)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (ring != nil) != tc.ring {
+				t.Fatalf("key ring: got %v, want %v", ring != nil, tc.ring)
+			}
+			if ring != nil && ring.CountEntities() != tc.accepted {
+				t.Errorf("accepted: got %d, want %d", ring.CountEntities(), tc.accepted)
+			}
+			pinned := s.Pinned("example.com")
+			if len(pinned) != tc.pinned {
+				t.Fatalf("pinned: got %d, want %d", len(pinned), tc.pinned)
+			}
+			if tc.pinned > 0 && pinned[0] != first.GetFingerprint() {
+				t.Errorf("pinned: got %s, want %s", pinned[0], first.GetFingerprint())
+			}
+		})
+	}
+}
+
+func TestPolicyUnmarshalText(t *testing.T) {
+	var p Policy
+	if err := p.UnmarshalText([]byte("reject")); err != nil || p != PolicyReject {
+		t.Errorf("got %q, %v", p, err)
+	}
+	if err := p.UnmarshalText([]byte("trust-all")); err == nil {
+		t.Error("invalid policy accepted")
+	}
+}
@@ -11,7 +11,7 @@ package util //revive:disable-line:var-naming
 import (
 	"context"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
@@ -157,7 +157,7 @@ func (lc *LoggingClient) log(method, url string) {
 	if lc.Log != nil {
 		lc.Log(method, url)
 	} else {
-		log.Printf("[%s]: %s\n", method, url)
+		slog.Debug("http", "method", method, "url", url)
 	}
 }
 
@@ -0,0 +1,183 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package util //revive:disable-line:var-naming
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// HostLimit are the limits of the requests to a host.
+type HostLimit struct {
+	// Rate is the average upper limit of requests per second.
+	// Zero means unlimited.
+	Rate float64 `toml:"rate"`
+	// MaxInFlight is the maximal number of concurrent requests.
+	// A request is in flight till the header of its response
+	// is received. Zero means unlimited.
+	MaxInFlight int `toml:"max_in_flight"`
+}
+
+// hostState is the state of the limits of a host.
+type hostState struct {
+	limiter *rate.Limiter
+	slots   chan struct{}
+}
+
+// HostLimiter keeps the rate limiters and the counters of
+// the requests in flight per host. It is safe for concurrent
+// use and meant to be shared by all clients talking to the hosts.
+type HostLimiter struct {
+	def   HostLimit
+	limit map[string]HostLimit
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+// NewHostLimiter creates a new HostLimiter. The limits of the hosts
+// given by name override the default limit.
+func NewHostLimiter(def HostLimit, hosts map[string]HostLimit) *HostLimiter {
+	limit := make(map[string]HostLimit, len(hosts))
+	for host, l := range hosts {
+		limit[strings.ToLower(host)] = l
+	}
+	return &HostLimiter{
+		def:   def,
+		limit: limit,
+		hosts: map[string]*hostState{},
+	}
+}
+
+// state returns the state of a host.
+func (hl *HostLimiter) state(host string) *hostState {
+	host = strings.ToLower(host)
+	hl.mu.Lock()
+	defer hl.mu.Unlock()
+	if hs := hl.hosts[host]; hs != nil {
+		return hs
+	}
+	l, ok := hl.limit[host]
+	if !ok {
+		l = hl.def
+	}
+	hs := &hostState{}
+	if l.Rate > 0 {
+		hs.limiter = rate.NewLimiter(rate.Limit(l.Rate), 1)
+	}
+	if l.MaxInFlight > 0 {
+		hs.slots = make(chan struct{}, l.MaxInFlight)
+	}
+	hl.hosts[host] = hs
+	return hs
+}
+
+// acquire waits till a request to the host is allowed.
+// The returned function has to be called when the request is done.
+func (hl *HostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	hs := hl.state(host)
+	if hs.slots != nil {
+		select {
+		case hs.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	release := func() {
+		if hs.slots != nil {
+			<-hs.slots
+		}
+	}
+	if hs.limiter != nil {
+		if err := hs.limiter.Wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+	}
+	return release, nil
+}
+
+// HostLimitingClient is a Client implementing rate throttling
+// and limiting the number of concurrent requests per host.
+type HostLimitingClient struct {
+	Client
+	Limiter *HostLimiter
+}
+
+// Do implements the respective method of the [Client] interface.
+func (hc *HostLimitingClient) Do(req *http.Request) (*http.Response, error) {
+	release, err := hc.Limiter.acquire(req.Context(), req.URL.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	// Not waiting for the body to be closed as callers
+	// may issue further requests before reading it.
+	defer release()
+	return hc.Client.Do(req)
+}
+
+// GetWithContext implements the respective method of the [ClientWithContext] interface.
+func (hc *HostLimitingClient) GetWithContext(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return hc.Do(req)
+}
+
+// Get implements the respective method of the [Client] interface.
+func (hc *HostLimitingClient) Get(url string) (*http.Response, error) {
+	return hc.GetWithContext(context.Background(), url)
+}
+
+// HeadWithContext implements the respective method of the [ClientWithContext] interface.
+func (hc *HostLimitingClient) HeadWithContext(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return hc.Do(req)
+}
+
+// Head implements the respective method of the [Client] interface.
+func (hc *HostLimitingClient) Head(url string) (*http.Response, error) {
+	return hc.HeadWithContext(context.Background(), url)
+}
+
+// PostWithContext implements the respective method of the [ClientWithContext] interface.
+func (hc *HostLimitingClient) PostWithContext(ctx context.Context, url, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return hc.Do(req)
+}
+
+// Post implements the respective method of the [Client] interface.
+func (hc *HostLimitingClient) Post(url, contentType string, body io.Reader) (*http.Response, error) {
+	return hc.PostWithContext(context.Background(), url, contentType, body)
+}
+
+// PostFormWithContext implements the respective method of the [ClientWithContext] interface.
+func (hc *HostLimitingClient) PostFormWithContext(ctx context.Context, url string, data url.Values) (*http.Response, error) {
+	return hc.PostWithContext(
+		ctx, url, "application/x-www-form-urlencoded", strings.NewReader(data.Encode()))
+}
+
+// PostForm implements the respective method of the [Client] interface.
+func (hc *HostLimitingClient) PostForm(url string, data url.Values) (*http.Response, error) {
+	return hc.PostFormWithContext(context.Background(), url, data)
+}
@@ -0,0 +1,95 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHostLimitingClientInFlight(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	// The test server listens on 127.0.0.1.
+	hc := &HostLimitingClient{
+		Client: server.Client(),
+		Limiter: NewHostLimiter(HostLimit{MaxInFlight: 5}, map[string]HostLimit{
+			"127.0.0.1": {MaxInFlight: 2},
+		}),
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := hc.Get(server.URL)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			res.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	if got := maxInFlight.Load(); got > 2 {
+		t.Errorf("requests in flight: got %d, want at most 2", got)
+	}
+}
+
+func TestHostLimitingClientRate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	hc := &HostLimitingClient{
+		Client:  server.Client(),
+		Limiter: NewHostLimiter(HostLimit{Rate: 50}, nil),
+	}
+
+	local := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+
+	get := func(u string, n int) time.Duration {
+		start := time.Now()
+		for range n {
+			res, err := hc.Get(u)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+		}
+		return time.Since(start)
+	}
+
+	// The first request is free, the other four wait 20ms each.
+	if d := get(server.URL, 5); d < 60*time.Millisecond {
+		t.Errorf("rate not limited: took %v", d)
+	}
+	// Other hosts have their own budget.
+	if d := get(local, 1); d > 15*time.Millisecond {
+		t.Errorf("other host limited: took %v", d)
+	}
+}
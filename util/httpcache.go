@@ -0,0 +1,416 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package util //revive:disable-line:var-naming
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CachingClient is a Client caching the responses of GET requests
+// in a directory on disk. Responses which are fresh according to
+// their Cache-Control or Expires headers are served without
+// contacting the server. Stale responses are revalidated with
+// conditional requests using their ETag and Last-Modified headers.
+// Requests which are already conditional are passed through.
+// Responses marked as private and responses to requests carrying
+// credentials are not stored unless they are marked as public.
+type CachingClient struct {
+	Client
+	// Dir is the directory to store the cached responses in.
+	// It is created if it does not exist.
+	Dir string
+	// Revalidate makes every request contact the server
+	// even if the cached response is still fresh.
+	Revalidate bool
+	// Credentials tells that the requests carry credentials not
+	// visible to the cache, e.g. client certificates or extra
+	// headers added by the wrapped client.
+	Credentials bool
+}
+
+// cacheEntry is the stored meta data of a cached response.
+// It is written as the first line of the cache file
+// followed by the body of the response.
+type cacheEntry struct {
+	URL           string      `json:"url"`
+	Status        string      `json:"status"`
+	StatusCode    int         `json:"status_code"`
+	Header        http.Header `json:"header"`
+	ContentLength int64       `json:"content_length"`
+	// Stored is the time the response was received or revalidated.
+	Stored time.Time `json:"stored"`
+}
+
+// cacheControl parses the Cache-Control header fields.
+func cacheControl(h http.Header) map[string]string {
+	cc := map[string]string{}
+	for _, field := range h.Values("Cache-Control") {
+		for directive := range strings.SplitSeq(field, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name == "" {
+				continue
+			}
+			cc[strings.ToLower(name)] = strings.Trim(value, `"`)
+		}
+	}
+	return cc
+}
+
+// lifetime returns the time span a response is fresh after it was sent.
+func lifetime(h http.Header, cc map[string]string) time.Duration {
+	if maxAge, ok := cc["max-age"]; ok {
+		if secs, err := strconv.Atoi(maxAge); err == nil && secs > 0 {
+			return time.Duration(secs) * time.Second
+		}
+		return 0
+	}
+	expires, err := http.ParseTime(h.Get("Expires"))
+	if err != nil {
+		return 0
+	}
+	date, err := http.ParseTime(h.Get("Date"))
+	if err != nil {
+		return 0
+	}
+	return max(expires.Sub(date), 0)
+}
+
+// fresh returns true if the cached response may be used
+// without revalidation.
+func (ce *cacheEntry) fresh(now time.Time) bool {
+	cc := cacheControl(ce.Header)
+	if _, ok := cc["no-cache"]; ok {
+		return false
+	}
+	age := now.Sub(ce.Stored)
+	if secs, err := strconv.Atoi(ce.Header.Get("Age")); err == nil && secs > 0 {
+		age += time.Duration(secs) * time.Second
+	}
+	return age < lifetime(ce.Header, cc)
+}
+
+// update merges the header of a 304 (Not Modified) response
+// into the cached one.
+func (ce *cacheEntry) update(h http.Header, now time.Time) {
+	for key, values := range h {
+		switch key {
+		case "Content-Length", "Content-Type", "Content-Encoding":
+			continue
+		}
+		ce.Header[key] = values
+	}
+	ce.Stored = now
+}
+
+// response creates a response from the cache.
+func (ce *cacheEntry) response(req *http.Request, body io.ReadCloser) *http.Response {
+	return &http.Response{
+		Status:        ce.Status,
+		StatusCode:    ce.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        ce.Header.Clone(),
+		Body:          body,
+		ContentLength: ce.ContentLength,
+		Request:       req,
+	}
+}
+
+// cacheableRequest returns true if the response to
+// the request may be taken from the cache.
+func cacheableRequest(req *http.Request) bool {
+	if req.Method != http.MethodGet {
+		return false
+	}
+	for _, key := range []string{"If-None-Match", "If-Modified-Since", "Range"} {
+		if req.Header.Get(key) != "" {
+			return false
+		}
+	}
+	_, noStore := cacheControl(req.Header)["no-store"]
+	return !noStore
+}
+
+// storable returns true if the response may be stored in the cache.
+// Responses to requests with credentials have to be marked as public.
+func storable(req *http.Request, res *http.Response, credentials bool) bool {
+	if res.StatusCode != http.StatusOK {
+		return false
+	}
+	cc := cacheControl(res.Header)
+	if _, ok := cc["no-store"]; ok {
+		return false
+	}
+	if _, ok := cc["private"]; ok {
+		return false
+	}
+	if credentials || req.Header.Get("Authorization") != "" {
+		if _, ok := cc["public"]; !ok {
+			return false
+		}
+	}
+	for _, field := range res.Header.Values("Vary") {
+		for name := range strings.SplitSeq(field, ",") {
+			if name = strings.TrimSpace(name); name != "" &&
+				!strings.EqualFold(name, "Accept-Encoding") {
+				return false
+			}
+		}
+	}
+	return res.Header.Get("ETag") != "" ||
+		res.Header.Get("Last-Modified") != "" ||
+		lifetime(res.Header, cc) > 0
+}
+
+// filename returns the name of the cache file of an URL.
+func (cc *CachingClient) filename(url string) string {
+	hash := sha256.Sum256([]byte(url))
+	return filepath.Join(cc.Dir, hex.EncodeToString(hash[:]))
+}
+
+// cacheBody is the body of a response read from a cache file.
+type cacheBody struct {
+	io.Reader
+	io.Closer
+}
+
+// load opens the cache file of an URL and returns the stored entry
+// and its body. It returns nil if there is no usable entry.
+func (cc *CachingClient) load(fname, url string) (*cacheEntry, io.ReadCloser) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, nil
+	}
+	r := bufio.NewReader(f)
+	line, err := r.ReadBytes('\n')
+	if err != nil {
+		f.Close()
+		return nil, nil
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(line, &entry); err != nil || entry.URL != url {
+		f.Close()
+		return nil, nil
+	}
+	return &entry, cacheBody{Reader: r, Closer: f}
+}
+
+// create starts a new cache file by writing the meta data
+// of an entry to a temporary file.
+func (cc *CachingClient) create(fname string, entry *cacheEntry) (*os.File, error) {
+	if err := os.MkdirAll(cc.Dir, 0o700); err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp(cc.Dir, "."+filepath.Base(fname)+"-*")
+	if err != nil {
+		return nil, err
+	}
+	line, err := json.Marshal(entry)
+	if err == nil {
+		_, err = f.Write(append(line, '\n'))
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return f, nil
+}
+
+// commit closes a temporary cache file and moves it into place.
+func commit(f *os.File, fname string, ok bool) {
+	if err := f.Close(); err != nil || !ok {
+		os.Remove(f.Name())
+		return
+	}
+	if err := os.Rename(f.Name(), fname); err != nil {
+		os.Remove(f.Name())
+	}
+}
+
+// refresh rewrites the cache file of a revalidated entry.
+// Errors are ignored as the cache is only an optimization.
+func (cc *CachingClient) refresh(fname string, entry *cacheEntry) {
+	old, err := os.Open(fname)
+	if err != nil {
+		return
+	}
+	defer old.Close()
+	r := bufio.NewReader(old)
+	if _, err := r.ReadBytes('\n'); err != nil {
+		return
+	}
+	f, err := cc.create(fname, entry)
+	if err != nil {
+		return
+	}
+	_, err = io.Copy(f, r)
+	commit(f, fname, err == nil)
+}
+
+// storingBody copies the body of a response into
+// a cache file while it is read.
+type storingBody struct {
+	io.ReadCloser
+	f      *os.File
+	fname  string
+	failed bool
+	done   bool
+}
+
+// Read implements [io.Reader].
+func (sb *storingBody) Read(p []byte) (int, error) {
+	n, err := sb.ReadCloser.Read(p)
+	if n > 0 && !sb.failed {
+		if _, werr := sb.f.Write(p[:n]); werr != nil {
+			sb.failed = true
+		}
+	}
+	if err == io.EOF && !sb.done {
+		// Only completely read bodies are stored.
+		sb.done = true
+		commit(sb.f, sb.fname, !sb.failed)
+	}
+	return n, err
+}
+
+// Close implements [io.Closer].
+func (sb *storingBody) Close() error {
+	if !sb.done {
+		sb.done = true
+		commit(sb.f, sb.fname, false)
+	}
+	return sb.ReadCloser.Close()
+}
+
+// store arranges the body of a response to be stored in the cache.
+func (cc *CachingClient) store(fname string, res *http.Response, now time.Time) {
+	f, err := cc.create(fname, &cacheEntry{
+		URL:           res.Request.URL.String(),
+		Status:        res.Status,
+		StatusCode:    res.StatusCode,
+		Header:        res.Header,
+		ContentLength: res.ContentLength,
+		Stored:        now,
+	})
+	if err != nil {
+		return
+	}
+	res.Body = &storingBody{ReadCloser: res.Body, f: f, fname: fname}
+}
+
+// Do implements the respective method of the [Client] interface.
+func (cc *CachingClient) Do(req *http.Request) (*http.Response, error) {
+	if !cacheableRequest(req) {
+		return cc.Client.Do(req)
+	}
+	key := req.URL.String()
+	fname := cc.filename(key)
+	entry, body := cc.load(fname, key)
+
+	send := req
+	if entry != nil {
+		if !cc.Revalidate && entry.fresh(time.Now()) {
+			return entry.response(req, body), nil
+		}
+		etag, lastModified := entry.Header.Get("ETag"), entry.Header.Get("Last-Modified")
+		if etag != "" || lastModified != "" {
+			send = req.Clone(req.Context())
+			if etag != "" {
+				send.Header.Set("If-None-Match", etag)
+			}
+			if lastModified != "" {
+				send.Header.Set("If-Modified-Since", lastModified)
+			}
+		}
+	}
+
+	res, err := cc.Client.Do(send)
+	if err != nil {
+		if body != nil {
+			body.Close()
+		}
+		return nil, err
+	}
+	now := time.Now()
+
+	if entry != nil {
+		if res.StatusCode == http.StatusNotModified && send != req {
+			drain(res)
+			entry.update(res.Header, now)
+			cc.refresh(fname, entry)
+			return entry.response(req, body), nil
+		}
+		body.Close()
+	}
+
+	switch {
+	case storable(req, res, cc.Credentials):
+		// Store under the requested URL even if redirected.
+		res.Request = req
+		cc.store(fname, res, now)
+	case entry != nil:
+		os.Remove(fname)
+	}
+	return res, nil
+}
+
+// GetWithContext implements the respective method of the [ClientWithContext] interface.
+func (cc *CachingClient) GetWithContext(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return cc.Do(req)
+}
+
+// Get implements the respective method of the [Client] interface.
+func (cc *CachingClient) Get(url string) (*http.Response, error) {
+	return cc.GetWithContext(context.Background(), url)
+}
+
+// HeadWithContext implements the respective method of the [ClientWithContext] interface.
+// HEAD requests are not cached.
+func (cc *CachingClient) HeadWithContext(ctx context.Context, url string) (*http.Response, error) {
+	if c, ok := cc.Client.(ClientWithContext); ok {
+		return c.HeadWithContext(ctx, url)
+	}
+	return cc.Client.Head(url)
+}
+
+// PostWithContext implements the respective method of the [ClientWithContext] interface.
+// POST requests are not cached.
+func (cc *CachingClient) PostWithContext(ctx context.Context, url, contentType string, body io.Reader) (*http.Response, error) {
+	if c, ok := cc.Client.(ClientWithContext); ok {
+		return c.PostWithContext(ctx, url, contentType, body)
+	}
+	return cc.Client.Post(url, contentType, body)
+}
+
+// PostFormWithContext implements the respective method of the [ClientWithContext] interface.
+// POST requests are not cached.
+func (cc *CachingClient) PostFormWithContext(ctx context.Context, url string, data url.Values) (*http.Response, error) {
+	if c, ok := cc.Client.(ClientWithContext); ok {
+		return c.PostFormWithContext(ctx, url, data)
+	}
+	return cc.Client.PostForm(url, data)
+}
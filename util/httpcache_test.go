@@ -0,0 +1,154 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package util
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCachingClient(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		cacheControl  string
+		etag          string
+		revalidate    bool
+		authorization bool
+		credentials   bool
+		wantRequests  int32
+		wantNotMod    int32
+	}{
+		{name: "etag", etag: `"v1"`, wantRequests: 3, wantNotMod: 2},
+		{name: "max-age", cacheControl: "max-age=3600", wantRequests: 1},
+		{name: "no-cache", cacheControl: "no-cache, max-age=3600", etag: `"v1"`, wantRequests: 3, wantNotMod: 2},
+		{name: "no-store", cacheControl: "no-store", etag: `"v1"`, wantRequests: 3},
+		{name: "no validators", wantRequests: 3},
+		{name: "revalidate", cacheControl: "max-age=3600", etag: `"v1"`, revalidate: true, wantRequests: 3, wantNotMod: 2},
+		{name: "private", cacheControl: "private, max-age=3600", etag: `"v1"`, wantRequests: 3},
+		{name: "authorization", cacheControl: "max-age=3600", etag: `"v1"`, authorization: true, wantRequests: 3},
+		{name: "authorization public", cacheControl: "public, max-age=3600", authorization: true, wantRequests: 1},
+		{name: "credentials", cacheControl: "max-age=3600", etag: `"v1"`, credentials: true, wantRequests: 3},
+		{name: "credentials public", cacheControl: "public, max-age=3600", credentials: true, wantRequests: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests, notModified atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				if tc.cacheControl != "" {
+					w.Header().Set("Cache-Control", tc.cacheControl)
+				}
+				if tc.etag != "" {
+					w.Header().Set("ETag", tc.etag)
+					if r.Header.Get("If-None-Match") == tc.etag {
+						notModified.Add(1)
+						w.WriteHeader(http.StatusNotModified)
+						return
+					}
+				}
+				io.WriteString(w, "content")
+			}))
+			defer server.Close()
+
+			cc := &CachingClient{
+				Client:      server.Client(),
+				Dir:         t.TempDir(),
+				Revalidate:  tc.revalidate,
+				Credentials: tc.credentials,
+			}
+
+			for i := range 3 {
+				req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if tc.authorization {
+					req.Header.Set("Authorization", "Bearer secret")
+				}
+				res, err := cc.Do(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				data, err := io.ReadAll(res.Body)
+				res.Body.Close()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if res.StatusCode != http.StatusOK || string(data) != "content" {
+					t.Fatalf("get %d: got %d %q", i, res.StatusCode, data)
+				}
+			}
+			if got := requests.Load(); got != tc.wantRequests {
+				t.Errorf("requests: got %d, want %d", got, tc.wantRequests)
+			}
+			if got := notModified.Load(); got != tc.wantNotMod {
+				t.Errorf("not modified: got %d, want %d", got, tc.wantNotMod)
+			}
+		})
+	}
+}
+
+func TestCachingClientPassesConditional(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		io.WriteString(w, "content")
+	}))
+	defer server.Close()
+
+	cc := &CachingClient{Client: server.Client(), Dir: t.TempDir()}
+
+	res, err := cc.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+
+	// The caller checks the server itself.
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("If-None-Match", `"v1"`)
+	if res, err = cc.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotModified {
+		t.Errorf("status: got %d, want %d", res.StatusCode, http.StatusNotModified)
+	}
+}
+
+func TestCacheEntryFresh(t *testing.T) {
+	now := time.Now()
+	date := now.Add(-time.Hour).UTC().Format(http.TimeFormat)
+	for _, tc := range []struct {
+		name   string
+		header http.Header
+		stored time.Time
+		want   bool
+	}{
+		{"max-age", http.Header{"Cache-Control": {"max-age=60"}}, now.Add(-time.Second), true},
+		{"max-age expired", http.Header{"Cache-Control": {"max-age=60"}}, now.Add(-time.Minute), false},
+		{"age", http.Header{"Cache-Control": {"max-age=60"}, "Age": {"59"}}, now.Add(-2 * time.Second), false},
+		{"expires", http.Header{"Date": {date}, "Expires": {now.Add(time.Hour).UTC().Format(http.TimeFormat)}}, now, true},
+		{"expires invalid", http.Header{"Date": {date}, "Expires": {"0"}}, now, false},
+		{"no-cache", http.Header{"Cache-Control": {"no-cache", "max-age=60"}}, now, false},
+		{"nothing", http.Header{}, now, false},
+	} {
+		entry := cacheEntry{Header: tc.header, Stored: tc.stored}
+		if got := entry.fresh(now); got != tc.want {
+			t.Errorf("%s: got %t, want %t", tc.name, got, tc.want)
+		}
+	}
+}
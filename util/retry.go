@@ -0,0 +1,207 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package util //revive:disable-line:var-naming
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultRetries is the default number of retries of a request.
+	DefaultRetries = 3
+	// DefaultRetryDelay is the default delay before the first retry.
+	DefaultRetryDelay = time.Second
+	// DefaultRetryMaxDelay is the default upper limit of the delay between retries.
+	DefaultRetryMaxDelay = time.Minute
+)
+
+// RetryingClient is a Client retrying idempotent requests (GET and HEAD)
+// which failed with a transport error or a transient status code
+// (408, 429, 502, 503 and 504). The delay between the attempts grows
+// exponentially with jitter. A Retry-After header sent by the server
+// is respected. If it asks to wait longer than MaxDelay the
+// response is returned as is.
+type RetryingClient struct {
+	Client
+	// Retries is the maximal number of retries of a request.
+	Retries int
+	// Delay is the delay before the first retry.
+	// Defaults to DefaultRetryDelay.
+	Delay time.Duration
+	// MaxDelay is the upper limit of the delay between retries.
+	// Defaults to DefaultRetryMaxDelay.
+	MaxDelay time.Duration
+	// Log is called before each retry if given.
+	Log func(method, url string, attempt int, wait time.Duration, reason string)
+}
+
+// retryableStatus returns true if a request failed with
+// this status code may succeed when retried.
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses the Retry-After header of a response
+// given in seconds or as HTTP date.
+func retryAfter(res *http.Response, now time.Time) (time.Duration, bool) {
+	value := res.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(secs)*time.Second, 0), true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+func (rc *RetryingClient) delay() time.Duration {
+	if rc.Delay > 0 {
+		return rc.Delay
+	}
+	return DefaultRetryDelay
+}
+
+func (rc *RetryingClient) maxDelay() time.Duration {
+	if rc.MaxDelay > 0 {
+		return rc.MaxDelay
+	}
+	return DefaultRetryMaxDelay
+}
+
+// backoff returns the delay before the given retry.
+// It doubles with every attempt up to MaxDelay. The
+// jitter takes a random value from the upper half.
+func (rc *RetryingClient) backoff(attempt int) time.Duration {
+	d, limit := rc.delay(), rc.maxDelay()
+	for range attempt {
+		if d >= limit/2 {
+			d = limit
+			break
+		}
+		d *= 2
+	}
+	d = min(d, limit)
+	return d/2 + rand.N(d/2+1)
+}
+
+// drain discards the body of a response which is not used.
+func drain(res *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64*1024))
+	res.Body.Close()
+}
+
+// Do implements the respective method of the [Client] interface.
+// Only GET and HEAD requests are retried.
+func (rc *RetryingClient) Do(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return rc.Client.Do(req)
+	}
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		res, err := rc.Client.Do(req)
+		if attempt >= rc.Retries || ctx.Err() != nil {
+			return res, err
+		}
+		var (
+			wait   time.Duration
+			reason string
+		)
+		switch {
+		case err != nil:
+			wait, reason = rc.backoff(attempt), err.Error()
+		case retryableStatus(res.StatusCode):
+			reason = res.Status
+			if after, ok := retryAfter(res, time.Now()); ok {
+				if after > rc.maxDelay() {
+					// The server asks us to come back much later.
+					return res, nil
+				}
+				wait = after
+			} else {
+				wait = rc.backoff(attempt)
+			}
+			drain(res)
+		default:
+			return res, nil
+		}
+		if rc.Log != nil {
+			rc.Log(req.Method, req.URL.String(), attempt+1, wait, reason)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// GetWithContext implements the respective method of the [ClientWithContext] interface.
+func (rc *RetryingClient) GetWithContext(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return rc.Do(req)
+}
+
+// Get implements the respective method of the [Client] interface.
+func (rc *RetryingClient) Get(url string) (*http.Response, error) {
+	return rc.GetWithContext(context.Background(), url)
+}
+
+// HeadWithContext implements the respective method of the [ClientWithContext] interface.
+func (rc *RetryingClient) HeadWithContext(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return rc.Do(req)
+}
+
+// Head implements the respective method of the [Client] interface.
+func (rc *RetryingClient) Head(url string) (*http.Response, error) {
+	return rc.HeadWithContext(context.Background(), url)
+}
+
+// PostWithContext implements the respective method of the [ClientWithContext] interface.
+// POST requests are not retried.
+func (rc *RetryingClient) PostWithContext(ctx context.Context, url, contentType string, body io.Reader) (*http.Response, error) {
+	if cc, ok := rc.Client.(ClientWithContext); ok {
+		return cc.PostWithContext(ctx, url, contentType, body)
+	}
+	return rc.Client.Post(url, contentType, body)
+}
+
+// PostFormWithContext implements the respective method of the [ClientWithContext] interface.
+// POST requests are not retried.
+func (rc *RetryingClient) PostFormWithContext(ctx context.Context, url string, data url.Values) (*http.Response, error) {
+	if cc, ok := rc.Client.(ClientWithContext); ok {
+		return cc.PostFormWithContext(ctx, url, data)
+	}
+	return rc.Client.PostForm(url, data)
+}
@@ -0,0 +1,102 @@
+// This file is Free Software under the Apache-2.0 License
+// without warranty, see README.md and LICENSES/Apache-2.0.txt for details.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// SPDX-FileCopyrightText: 2026 German Federal Office for Information Security (BSI) <https://www.bsi.bund.de>
+// Software-Engineering: 2026 Intevation GmbH <https://intevation.de>
+
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryingClient(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		method     string
+		fail       int
+		status     int
+		retryAfter string
+		retries    int
+		wantCalls  int32
+		wantStatus int
+	}{
+		{"success", http.MethodGet, 0, 0, "", 3, 1, http.StatusOK},
+		{"recovers", http.MethodGet, 2, http.StatusServiceUnavailable, "", 3, 3, http.StatusOK},
+		{"too many requests", http.MethodHead, 1, http.StatusTooManyRequests, "0", 3, 2, http.StatusOK},
+		{"gives up", http.MethodGet, 5, http.StatusBadGateway, "", 2, 3, http.StatusBadGateway},
+		{"permanent", http.MethodGet, 5, http.StatusNotFound, "", 3, 1, http.StatusNotFound},
+		{"retry after too long", http.MethodGet, 5, http.StatusServiceUnavailable, "3600", 3, 1, http.StatusServiceUnavailable},
+		{"no retry of post", http.MethodPost, 5, http.StatusServiceUnavailable, "", 3, 1, http.StatusServiceUnavailable},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if int(calls.Add(1)) <= tc.fail {
+					if tc.retryAfter != "" {
+						w.Header().Set("Retry-After", tc.retryAfter)
+					}
+					w.WriteHeader(tc.status)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			var retries int
+			rc := &RetryingClient{
+				Client:   server.Client(),
+				Retries:  tc.retries,
+				Delay:    time.Millisecond,
+				MaxDelay: 10 * time.Millisecond,
+				Log: func(string, string, int, time.Duration, string) {
+					retries++
+				},
+			}
+
+			var (
+				res *http.Response
+				err error
+			)
+			switch tc.method {
+			case http.MethodHead:
+				res, err = rc.Head(server.URL)
+			case http.MethodPost:
+				res, err = rc.Post(server.URL, "text/plain", strings.NewReader("x"))
+			default:
+				res, err = rc.Get(server.URL)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			if res.StatusCode != tc.wantStatus {
+				t.Errorf("status: got %d, want %d", res.StatusCode, tc.wantStatus)
+			}
+			if got := calls.Load(); got != tc.wantCalls {
+				t.Errorf("calls: got %d, want %d", got, tc.wantCalls)
+			}
+			if retries != int(tc.wantCalls)-1 {
+				t.Errorf("logged retries: got %d, want %d", retries, tc.wantCalls-1)
+			}
+		})
+	}
+}
+
+func TestRetryingClientBackoff(t *testing.T) {
+	rc := &RetryingClient{Delay: time.Second, MaxDelay: 5 * time.Second}
+	for attempt, want := range []time.Duration{
+		time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second,
+	} {
+		if got := rc.backoff(attempt); got < want/2 || got > want {
+			t.Errorf("attempt %d: got %v, want between %v and %v", attempt, got, want/2, want)
+		}
+	}
+}